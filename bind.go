@@ -2,12 +2,11 @@ package zest
 
 import (
 	"encoding"
-	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"mime/multipart"
 	"net/http"
-	"net/url"
+	"net/textproto"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -18,6 +17,19 @@ type Validator interface {
 	Validate() error
 }
 
+// BindUnmarshaler 自定义参数解析接口
+// 绑定 param、query、form、header 时，字段类型（或其指针）实现了该接口就调用 UnmarshalParam，优先于内置的类型转换
+// 例如将查询参数 "2024-01-02" 解析为自定义的 Date 类型；没有实现时会再尝试 encoding.TextUnmarshaler，
+// 因此 time.Time（RFC 3339）、net.IP、netip.Addr 等标准库类型无需额外代码即可绑定
+// JSON 请求体仍由 JSONSerializer 解码，自定义类型照常实现 json.Unmarshaler 即可
+type BindUnmarshaler interface {
+	UnmarshalParam(param string) error
+}
+
+// Bind 依次绑定路径参数、查询参数（GET、DELETE、HEAD）和请求体，最后调用 dst.Validate
+// 引擎开启 DisallowUnknownFields 时，JSON 请求体中包含 dst 没有的字段会返回 400
+// multipart 表单按 form 标签同时绑定普通字段和文件字段，文件字段的类型为 *multipart.FileHeader 或 []*multipart.FileHeader，
+// 切片接收同名字段上传的所有文件，单个指针只取第一个；文件字段标记为 `form:"avatar,required"` 时，缺少该文件返回 400
 func (c *Context) Bind(dst Validator) error {
 	if err := bindPathValues(c.Request, dst); err != nil {
 		return err
@@ -27,12 +39,12 @@ func (c *Context) Bind(dst Validator) error {
 	if method == http.MethodGet ||
 		method == http.MethodDelete ||
 		method == http.MethodHead {
-		if err := bindQueryParams(c.Request, dst); err != nil {
+		if err := bindQueryParams(c, dst); err != nil {
 			return err
 		}
 	}
 
-	if err := bindBody(c.Request, dst); err != nil {
+	if err := bindBody(c, dst); err != nil {
 		return err
 	}
 
@@ -43,7 +55,40 @@ func (c *Context) Bind(dst Validator) error {
 	return nil
 }
 
+// BindWithLimit 与 Bind 相同，但请求体大小上限使用 limit 而不是引擎的 BindBodyLimit
+// limit 小于 0 时不限制，用于需要接收大请求体的接口
+func (c *Context) BindWithLimit(dst Validator, limit int64) error {
+	c.bindLimit = limit
+	defer func() { c.bindLimit = 0 }()
+	return c.Bind(dst)
+}
+
+// BindStrict 与 Bind 相同，但无论引擎是否开启 DisallowUnknownFields，JSON 请求体中的未知字段都会返回 400
+func (c *Context) BindStrict(dst Validator) error {
+	c.strictJSON = true
+	defer func() { c.strictJSON = false }()
+	return c.Bind(dst)
+}
+
+// BindHeader 将请求头绑定到 dst 中带 header 标签的字段，如 `header:"X-Tenant-ID"`
+// 标签名按 textproto.CanonicalMIMEHeaderKey 规范化后匹配，大小写不敏感
+// 支持 string、int 等基本类型，[]string 等切片字段接收同名请求头的所有值
+// 值无法转换时返回 400 HTTPError，错误信息中包含对应的请求头名称
+func (c *Context) BindHeader(dst any) error {
+	if err := bindData(dst, c.Request.Header, "header", nil); err != nil {
+		var fe *fieldError
+		if errors.As(err, &fe) {
+			return NewHTTPError(http.StatusBadRequest, "invalid header "+textproto.CanonicalMIMEHeaderKey(fe.Field)).Wrap(err)
+		}
+		return NewHTTPError(http.StatusBadRequest).Wrap(err)
+	}
+	return nil
+}
+
 const defaultMemory = 32 << 20 // 32 MB
+
+// defaultBindBodyLimit Bind 默认的请求体大小上限
+const defaultBindBodyLimit = 1 << 20 // 1 MB
 var (
 	// NOT supported by bind as you can NOT check easily empty struct being actual file or not
 	multipartFileHeaderType = reflect.TypeFor[multipart.FileHeader]()
@@ -56,6 +101,17 @@ var (
 	pathParamRegex = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
 )
 
+// bindBodyLimit 返回本次绑定的请求体大小上限，小于等于 0 表示不限制
+func (c *Context) bindBodyLimit() int64 {
+	if c.bindLimit != 0 {
+		return c.bindLimit
+	}
+	if c.zest != nil && c.zest.BindBodyLimit != 0 {
+		return c.zest.BindBodyLimit
+	}
+	return defaultBindBodyLimit
+}
+
 // tag: param
 func bindPathValues(req *http.Request, dst Validator) error {
 	names := getPathParamNames(req.Pattern)
@@ -72,24 +128,43 @@ func bindPathValues(req *http.Request, dst Validator) error {
 }
 
 // tag: query
-func bindQueryParams(req *http.Request, dst Validator) error {
-	if err := bindData(dst, req.URL.Query(), "query", nil); err != nil {
+func bindQueryParams(c *Context, dst Validator) error {
+	if err := bindData(dst, c.QueryParamsAll(), "query", nil); err != nil {
 		return NewHTTPError(http.StatusBadRequest).Wrap(err)
 	}
 	return nil
 }
 
 // tag: json
-func bindBody(req *http.Request, dst Validator) (err error) {
+func bindBody(c *Context, dst Validator) (err error) {
+	req := c.Request
 	if req.ContentLength == 0 {
 		return
 	}
 	base, _, _ := strings.Cut(req.Header.Get(HeaderContentType), ";")
 	mediaType := strings.TrimSpace(base)
 
+	// multipart 表单通常用于上传文件，由 MultipartMemoryLimit 控制内存占用，不受该上限限制
+	if limit := c.bindBodyLimit(); limit > 0 && mediaType != MIMEMultipartForm {
+		if req.ContentLength > limit {
+			return NewHTTPError(http.StatusRequestEntityTooLarge)
+		}
+		req.Body = http.MaxBytesReader(c.response.ResponseWriter, req.Body, limit)
+		defer func() {
+			var mbe *http.MaxBytesError
+			if errors.As(err, &mbe) {
+				err = NewHTTPError(http.StatusRequestEntityTooLarge).Wrap(mbe)
+			}
+		}()
+	}
+
 	switch mediaType {
 	case MIMEApplicationJSON:
-		if err = json.NewDecoder(req.Body).Decode(dst); err != nil {
+		if err = c.jsonSerializer().Deserialize(c, dst); err != nil {
+			// encoding/json 的错误信息为 json: unknown field "name"
+			if msg, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+				return NewHTTPError(http.StatusBadRequest, "unknown field "+msg).Wrap(err)
+			}
 			return NewHTTPError(http.StatusBadRequest).Wrap(err)
 		}
 	case MIMEApplicationXML, MIMETextXML:
@@ -97,7 +172,7 @@ func bindBody(req *http.Request, dst Validator) (err error) {
 			return NewHTTPError(http.StatusBadRequest).Wrap(err)
 		}
 	case MIMEApplicationForm:
-		params, err := formParams(req)
+		params, err := c.FormParams()
 		if err != nil {
 			return NewHTTPError(http.StatusBadRequest).Wrap(err)
 		}
@@ -105,11 +180,15 @@ func bindBody(req *http.Request, dst Validator) (err error) {
 			return NewHTTPError(http.StatusBadRequest).Wrap(err)
 		}
 	case MIMEMultipartForm:
-		if err = req.ParseMultipartForm(defaultMemory); err != nil {
+		if err = req.ParseMultipartForm(c.multipartMemoryLimit()); err != nil {
 			return NewHTTPError(http.StatusBadRequest).Wrap(err)
 		}
 		params := req.MultipartForm
 		if err = bindData(dst, params.Value, "form", params.File); err != nil {
+			var fe *fieldError
+			if errors.As(err, &fe) && errors.Is(fe.Err, errMissingFile) {
+				return NewHTTPError(http.StatusBadRequest, "missing file "+fe.Field).Wrap(err)
+			}
 			return NewHTTPError(http.StatusBadRequest).Wrap(err)
 		}
 	default:
@@ -134,10 +213,11 @@ func bindData(
 	tag string,
 	dataFiles map[string][]*multipart.FileHeader,
 ) error {
-	if dst == nil || (len(data) == 0 && len(dataFiles) == 0) {
+	// multipart 表单即使没有任何值也要继续，用于检查必填的文件字段
+	if dst == nil || (len(data) == 0 && dataFiles == nil) {
 		return nil
 	}
-	hasFiles := len(dataFiles) > 0
+	isMultipart := dataFiles != nil
 	typ := reflect.TypeOf(dst).Elem()
 	val := reflect.ValueOf(dst).Elem()
 
@@ -193,7 +273,8 @@ func bindData(
 			continue
 		}
 		structFieldKind := structField.Kind()
-		inputFieldName := typeField.Tag.Get(tag)
+		// 标签选项目前只有 required，只对文件字段生效，如 `form:"avatar,required"`
+		inputFieldName, tagOptions, _ := strings.Cut(typeField.Tag.Get(tag), ",")
 		if typeField.Anonymous && structFieldKind == reflect.Struct && inputFieldName != "" {
 			// if anonymous struct with query/param/form tags, report an error
 			return errors.New("query/param/form tags are not allowed with anonymous struct field")
@@ -201,8 +282,8 @@ func bindData(
 
 		if inputFieldName == "" {
 			// If tag is nil, we inspect if the field is a not BindUnmarshaler struct and try to bind data into it (might contain fields with tags).
-			// structs that implement BindUnmarshaler are bound only when they have explicit tag
-			if _, ok := structField.Addr().Interface().(interface{ UnmarshalParam(param string) error }); !ok && structFieldKind == reflect.Struct {
+			// structs that implement BindUnmarshaler or encoding.TextUnmarshaler (e.g. time.Time) are bound only when they have explicit tag
+			if !isFieldUnmarshaler(structField) && structFieldKind == reflect.Struct {
 				if err := bindData(structField.Addr().Interface(), data, tag, dataFiles); err != nil {
 					return err
 				}
@@ -211,13 +292,14 @@ func bindData(
 			continue
 		}
 
-		if hasFiles {
+		if isMultipart {
 			if ok, err := isFieldMultipartFile(structField.Type()); err != nil {
 				return err
 			} else if ok {
-				if ok := setMultipartFileHeaderTypes(structField, inputFieldName, dataFiles); ok {
-					continue
+				if !setMultipartFileHeaderTypes(structField, inputFieldName, dataFiles) && tagOptions == "required" {
+					return &fieldError{Field: inputFieldName, Err: errMissingFile}
 				}
+				continue
 			}
 		}
 
@@ -246,14 +328,14 @@ func bindData(
 		// try unmarshalling first, in case we're dealing with an alias to an array type
 		if ok, err := unmarshalInputsToField(typeField.Type.Kind(), inputValue, structField); ok {
 			if err != nil {
-				return err
+				return &fieldError{Field: inputFieldName, Err: err}
 			}
 			continue
 		}
 
 		if ok, err := unmarshalInputToField(typeField.Type.Kind(), inputValue[0], structField); ok {
 			if err != nil {
-				return err
+				return &fieldError{Field: inputFieldName, Err: err}
 			}
 			continue
 		}
@@ -271,7 +353,7 @@ func bindData(
 			slice := reflect.MakeSlice(structField.Type(), numElems, numElems)
 			for j := range numElems {
 				if err := setWithProperType(sliceOf, inputValue[j], slice.Index(j)); err != nil {
-					return err
+					return &fieldError{Field: inputFieldName, Err: err}
 				}
 			}
 			structField.Set(slice)
@@ -279,12 +361,29 @@ func bindData(
 		}
 
 		if err := setWithProperType(structFieldKind, inputValue[0], structField); err != nil {
-			return err
+			return &fieldError{Field: inputFieldName, Err: err}
 		}
 	}
 	return nil
 }
 
+// errMissingFile multipart 表单中缺少标记为 required 的文件
+var errMissingFile = errors.New("required file is missing")
+
+// fieldError 某个字段的值无法转换时返回的错误，Field 为标签中的名称
+type fieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.Err
+}
+
 func isFieldMultipartFile(field reflect.Type) (bool, error) {
 	switch field {
 	case multipartFileHeaderPointerType,
@@ -342,6 +441,15 @@ func unmarshalInputsToField(valueKind reflect.Kind, values []string, field refle
 	return true, unmarshaler.UnmarshalParams(values)
 }
 
+// isFieldUnmarshaler 字段（的指针）实现了 BindUnmarshaler 或 encoding.TextUnmarshaler，整体作为一个值解析
+func isFieldUnmarshaler(field reflect.Value) bool {
+	switch field.Addr().Interface().(type) {
+	case BindUnmarshaler, encoding.TextUnmarshaler:
+		return true
+	}
+	return false
+}
+
 func unmarshalInputToField(valueKind reflect.Kind, val string, field reflect.Value) (bool, error) {
 	if valueKind == reflect.Pointer {
 		if field.IsNil() {
@@ -352,7 +460,7 @@ func unmarshalInputToField(valueKind reflect.Kind, val string, field reflect.Val
 
 	fieldIValue := field.Addr().Interface()
 	switch unmarshaler := fieldIValue.(type) {
-	case interface{ UnmarshalParam(param string) error }:
+	case BindUnmarshaler:
 		return true, unmarshaler.UnmarshalParam(val)
 	case encoding.TextUnmarshaler:
 		return true, unmarshaler.UnmarshalText([]byte(val))
@@ -447,16 +555,3 @@ func setFloatField(value string, bitSize int, field reflect.Value) error {
 	}
 	return err
 }
-
-func formParams(r *http.Request) (url.Values, error) {
-	if strings.HasPrefix(r.Header.Get(HeaderContentType), MIMEMultipartForm) {
-		if err := r.ParseMultipartForm(defaultMemory); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := r.ParseForm(); err != nil {
-			return nil, err
-		}
-	}
-	return r.Form, nil
-}
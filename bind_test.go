@@ -0,0 +1,105 @@
+package zest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+type textBindInput struct {
+	At   time.Time  `query:"at" form:"at"`
+	IP   net.IP     `query:"ip" form:"ip"`
+	Addr netip.Addr `param:"addr"`
+	Til  *time.Time `query:"til"`
+}
+
+func (textBindInput) Validate() error { return nil }
+
+func TestBindTextUnmarshaler(t *testing.T) {
+	z := New()
+	handler := func(c *Context) error {
+		var in textBindInput
+		if err := c.Bind(&in); err != nil {
+			return err
+		}
+		til := "nil"
+		if in.Til != nil {
+			til = in.Til.UTC().Format(time.RFC3339)
+		}
+		return c.String(http.StatusOK, fmt.Sprintf("%s %s %s %s", in.At.UTC().Format(time.RFC3339), in.IP, in.Addr, til))
+	}
+	z.GET("/hosts/{addr}", handler)
+	z.POST("/hosts/{addr}", handler)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		form       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "query",
+			method:     http.MethodGet,
+			target:     "/hosts/192.168.1.1?at=2024-03-09T16:05:07%2B08:00&ip=10.0.0.1",
+			wantStatus: http.StatusOK,
+			wantBody:   "2024-03-09T08:05:07Z 10.0.0.1 192.168.1.1 nil",
+		},
+		{
+			name:       "ipv6 and pointer",
+			method:     http.MethodGet,
+			target:     "/hosts/::1?ip=2001:db8::1&til=2024-01-02T03:04:05Z",
+			wantStatus: http.StatusOK,
+			wantBody:   "0001-01-01T00:00:00Z 2001:db8::1 ::1 2024-01-02T03:04:05Z",
+		},
+		{
+			name:       "form",
+			method:     http.MethodPost,
+			target:     "/hosts/10.1.1.1",
+			form:       "at=2024-03-09T16:05:07Z&ip=10.0.0.1",
+			wantStatus: http.StatusOK,
+			wantBody:   "2024-03-09T16:05:07Z 10.0.0.1 10.1.1.1 nil",
+		},
+		{
+			name:       "invalid time",
+			method:     http.MethodGet,
+			target:     "/hosts/10.1.1.1?at=2024-03-09",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid ip",
+			method:     http.MethodGet,
+			target:     "/hosts/10.1.1.1?ip=10.0.0.300",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid path param",
+			method:     http.MethodGet,
+			target:     "/hosts/not-an-ip",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.form))
+			if tt.form != "" {
+				req.Header.Set(HeaderContentType, MIMEApplicationForm)
+			}
+			rec := z.Test(req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %q", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
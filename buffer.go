@@ -0,0 +1,142 @@
+package zest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// bufferedWriter 缓冲不超过 limit 字节的响应，请求结束时设置 Content-Length 后一次写出
+// 响应超过 limit 或调用 Flush 时转为透传，之后的写入直接发送，与未开启缓冲时相同
+type bufferedWriter struct {
+	http.ResponseWriter
+	limit int
+	// head HEAD 请求的响应体已被丢弃，缓冲区的长度不是真实的 Content-Length
+	head bool
+
+	status      int
+	buf         bytes.Buffer
+	passthrough bool
+}
+
+var bufferedWriterPool = sync.Pool{
+	New: func() any {
+		return new(bufferedWriter)
+	},
+}
+
+func acquireBufferedWriter(w http.ResponseWriter, r *http.Request, limit int) *bufferedWriter {
+	bw := bufferedWriterPool.Get().(*bufferedWriter)
+	bw.ResponseWriter = w
+	bw.limit = limit
+	bw.head = r.Method == http.MethodHead
+	return bw
+}
+
+func releaseBufferedWriter(bw *bufferedWriter) {
+	bw.ResponseWriter = nil
+	bw.status = 0
+	bw.passthrough = false
+	// 不保留超过上限的缓冲区，避免一次大响应长期占用池中的内存
+	if bw.buf.Cap() > bw.limit {
+		bw.buf = bytes.Buffer{}
+	}
+	bw.buf.Reset()
+	bufferedWriterPool.Put(bw)
+}
+
+func (bw *bufferedWriter) WriteHeader(code int) {
+	if bw.passthrough {
+		bw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	// 1xx 信息响应（如 103 Early Hints）需要立即发出；101 之后连接不再是 HTTP，不能继续缓冲
+	if code < http.StatusOK {
+		if code == http.StatusSwitchingProtocols {
+			bw.passthrough = true
+		}
+		bw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if bw.status == 0 {
+		bw.status = code
+	}
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	if !bw.passthrough && bw.buf.Len()+len(b) > bw.limit {
+		if err := bw.startPassthrough(); err != nil {
+			return 0, err
+		}
+	}
+	if bw.passthrough {
+		return bw.ResponseWriter.Write(b)
+	}
+	return bw.buf.Write(b)
+}
+
+func (bw *bufferedWriter) WriteString(s string) (int, error) {
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	if !bw.passthrough && bw.buf.Len()+len(s) > bw.limit {
+		if err := bw.startPassthrough(); err != nil {
+			return 0, err
+		}
+	}
+	if bw.passthrough {
+		return io.WriteString(bw.ResponseWriter, s)
+	}
+	return bw.buf.WriteString(s)
+}
+
+// Flush 流式响应需要立即发送，转为透传
+func (bw *bufferedWriter) Flush() {
+	if !bw.passthrough {
+		if bw.status == 0 {
+			bw.status = http.StatusOK
+		}
+		if err := bw.startPassthrough(); err != nil {
+			return
+		}
+	}
+	if f, ok := bw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (bw *bufferedWriter) Unwrap() http.ResponseWriter {
+	return bw.ResponseWriter
+}
+
+func (bw *bufferedWriter) startPassthrough() error {
+	bw.passthrough = true
+	bw.ResponseWriter.WriteHeader(bw.status)
+	_, err := bw.ResponseWriter.Write(bw.buf.Bytes())
+	bw.buf.Reset()
+	return err
+}
+
+// finish 写出缓冲的响应，处理器没有设置 Content-Length 时按缓冲区的长度设置
+func (bw *bufferedWriter) finish() {
+	if bw.passthrough || bw.status == 0 {
+		return
+	}
+	header := bw.Header()
+	if !bw.head && bodyAllowed(bw.status) &&
+		header.Get(HeaderContentLength) == "" && header.Get("Transfer-Encoding") == "" {
+		header.Set(HeaderContentLength, strconv.Itoa(bw.buf.Len()))
+	}
+	bw.ResponseWriter.WriteHeader(bw.status)
+	_, _ = bw.ResponseWriter.Write(bw.buf.Bytes())
+}
+
+// bodyAllowed 状态码是否允许携带响应体
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified
+}
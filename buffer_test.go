@@ -0,0 +1,172 @@
+package zest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// codeRecorder 记录底层 ResponseWriter 收到的每个状态码，1xx 不转发给 ResponseRecorder
+type codeRecorder struct {
+	*httptest.ResponseRecorder
+	codes []int
+}
+
+func (w *codeRecorder) WriteHeader(code int) {
+	w.codes = append(w.codes, code)
+	if code >= http.StatusOK {
+		w.ResponseRecorder.WriteHeader(code)
+	}
+}
+
+func (w *codeRecorder) Flush() {
+	w.ResponseRecorder.Flush()
+}
+
+func TestResponseBuffer(t *testing.T) {
+	const limit = 16
+	small := "hello"
+	large := strings.Repeat("x", limit+1)
+
+	tests := []struct {
+		name      string
+		method    string
+		handler   func(c *Context, w *codeRecorder) error
+		wantCodes []int
+		wantBody  string
+		// wantLength 为空表示不应该有 Content-Length
+		wantLength string
+	}{
+		{
+			name:       "content length",
+			handler:    func(c *Context, w *codeRecorder) error { return c.String(http.StatusCreated, small) },
+			wantCodes:  []int{http.StatusCreated},
+			wantBody:   small,
+			wantLength: "5",
+		},
+		{
+			name: "buffered until the end",
+			handler: func(c *Context, w *codeRecorder) error {
+				_ = c.String(http.StatusOK, small)
+				if len(w.codes) != 0 || w.Body.Len() != 0 {
+					t.Error("response was sent before the handler returned")
+				}
+				return nil
+			},
+			wantCodes:  []int{http.StatusOK},
+			wantBody:   small,
+			wantLength: "5",
+		},
+		{
+			name: "pass-through after limit",
+			handler: func(c *Context, w *codeRecorder) error {
+				_ = c.String(http.StatusOK, small)
+				_, _ = c.Response().WriteString(large)
+				if w.Body.String() != small+large {
+					t.Errorf("body sent before return = %q, want everything written so far", w.Body.String())
+				}
+				_, _ = c.Response().WriteString("!")
+				return nil
+			},
+			wantCodes: []int{http.StatusOK},
+			wantBody:  small + large + "!",
+		},
+		{
+			name: "flush switches to pass-through",
+			handler: func(c *Context, w *codeRecorder) error {
+				_ = c.String(http.StatusOK, small)
+				c.Response().Flush()
+				if !w.Flushed || w.Body.String() != small {
+					t.Errorf("after Flush flushed = %v, body = %q", w.Flushed, w.Body.String())
+				}
+				return nil
+			},
+			wantCodes: []int{http.StatusOK},
+			wantBody:  small,
+		},
+		{
+			name:      "head has no body length",
+			method:    http.MethodHead,
+			handler:   func(c *Context, w *codeRecorder) error { return c.String(http.StatusOK, small) },
+			wantCodes: []int{http.StatusOK},
+		},
+		{
+			name:      "no content",
+			handler:   func(c *Context, w *codeRecorder) error { return c.NoContent(http.StatusNoContent) },
+			wantCodes: []int{http.StatusNoContent},
+		},
+		{
+			name:      "not modified",
+			handler:   func(c *Context, w *codeRecorder) error { return c.NoContent(http.StatusNotModified) },
+			wantCodes: []int{http.StatusNotModified},
+		},
+		{
+			name: "1xx passed through",
+			handler: func(c *Context, w *codeRecorder) error {
+				c.ResponseWriter().WriteHeader(http.StatusEarlyHints)
+				if !slices.Equal(w.codes, []int{http.StatusEarlyHints}) {
+					t.Errorf("codes after 103 = %v, want [103] sent immediately", w.codes)
+				}
+				return c.String(http.StatusOK, small)
+			},
+			wantCodes:  []int{http.StatusEarlyHints, http.StatusOK},
+			wantBody:   small,
+			wantLength: "5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			w := &codeRecorder{ResponseRecorder: httptest.NewRecorder()}
+			z := New()
+			z.ResponseBufferSize = limit
+			z.GET("/", func(c *Context) error { return tt.handler(c, w) })
+
+			z.ServeHTTP(w, httptest.NewRequest(method, "/", nil))
+
+			if !slices.Equal(w.codes, tt.wantCodes) {
+				t.Errorf("status codes = %v, want %v", w.codes, tt.wantCodes)
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if got := w.Result().Header.Get(HeaderContentLength); got != tt.wantLength {
+				t.Errorf("Content-Length = %q, want %q", got, tt.wantLength)
+			}
+		})
+	}
+}
+
+func BenchmarkResponseBuffer(b *testing.B) {
+	body := strings.Repeat("x", 8<<10)
+	benchmarks := []struct {
+		name string
+		size int
+	}{
+		{"off", 0},
+		{"on", 64 << 10},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			z := New()
+			z.ResponseBufferSize = bm.size
+			z.GET("/", func(c *Context) error { return c.String(http.StatusOK, body) })
+			w := &discardResponseWriter{header: make(http.Header)}
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for b.Loop() {
+				clear(w.header)
+				z.ServeHTTP(w, r)
+			}
+		})
+	}
+}
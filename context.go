@@ -3,13 +3,20 @@ package zest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Context struct {
@@ -19,14 +26,30 @@ type Context struct {
 	Method   string
 	store    Map
 	zest     *Zest
+	// query 缓存解析后的查询参数，同一个请求内只解析一次
+	query url.Values
+	// strictJSON 由 BindStrict 在绑定期间设置
+	strictJSON bool
+	// bindLimit 由 BindWithLimit 在绑定期间设置，0 表示使用引擎的 BindBodyLimit
+	bindLimit int64
+	// logger 请求级别的 logger，由 SetLogger 设置
+	logger *slog.Logger
+	// handled 已经调用过 c.Error，同一个请求只处理一次错误
+	handled bool
 }
 
 // Response嵌入http.ResponseWriter 并提供了状态和大小追踪
 type Response struct {
+	// ResponseWriter 底层的 ResponseWriter，直接写入会绕过状态码和大小的记录，也会丢失 SetStatus 设置的状态码
+	// 需要原始 ResponseWriter 时使用 c.ResponseWriter()
 	http.ResponseWriter
-	Status    int
-	Size      int64
+	Status int
+	Size   int64
+	// Committed 响应头已经写出（WriteHeader 或第一次 Write），之后状态码和响应头都不能再修改
+	// 为 true 时 c.Error 不再调用错误处理器，避免在已经写出的响应后面追加错误内容
 	Committed bool
+	// pending 通过 SetStatus 设置了状态码但还没有写出响应头
+	pending bool
 }
 
 func (r *Response) WriteHeader(code int) {
@@ -38,6 +61,13 @@ func (r *Response) WriteHeader(code int) {
 	r.Committed = true
 }
 
+// writePending 写出通过 SetStatus 设置但还没有写出的状态码
+func (r *Response) writePending() {
+	if r.pending && !r.Committed {
+		r.WriteHeader(r.Status)
+	}
+}
+
 func (r *Response) Write(b []byte) (int, error) {
 	if !r.Committed {
 		if r.Status == 0 {
@@ -62,17 +92,38 @@ func (r *Response) WriteString(s string) (int, error) {
 	return n, err
 }
 
+// Flush 实现 http.Flusher，未提交时先写入状态码；底层不支持时什么也不做
+func (r *Response) Flush() {
+	if !r.Committed {
+		if r.Status == 0 {
+			r.Status = http.StatusOK
+		}
+		r.WriteHeader(r.Status)
+	}
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap 返回底层的 ResponseWriter，供 http.ResponseController 使用
+func (r *Response) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func NewContext(w http.ResponseWriter, r *http.Request) *Context {
 	c := &Context{}
 	c.reset(w, r)
 	return c
 }
 
+// reset 重置 Context 的所有状态，store 中的值会被清空（保留 map 以便复用），Request 和 ResponseWriter 替换为新的值
+// 路径参数由 Request.PathValue 提供，随 Request 一起替换，不会残留上一个请求的值
 func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
 	c.response.ResponseWriter = w
 	c.response.Status = http.StatusOK
 	c.response.Size = 0
 	c.response.Committed = false
+	c.response.pending = false
 
 	c.Request = r
 	if r != nil {
@@ -87,10 +138,18 @@ func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
 	if c.store != nil {
 		clear(c.store)
 	}
+	c.query = nil
+	c.strictJSON = false
+	c.bindLimit = 0
+	c.logger = nil
+	c.handled = false
 	c.zest = nil
 }
 
 func (c *Context) sync(w http.ResponseWriter, r *http.Request) {
+	if r != c.Request {
+		c.query = nil
+	}
 	c.Request = r
 	c.response.ResponseWriter = w
 	if r != nil {
@@ -99,16 +158,65 @@ func (c *Context) sync(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Zest 返回处理当前请求的引擎，通过 NewContext 单独创建的 Context 返回 nil
+func (c *Context) Zest() *Zest {
+	return c.zest
+}
+
 func (c *Context) Context() context.Context {
 	return c.Request.Context()
 }
 
+// Logger 返回当前请求的 *slog.Logger
+// 安装 middleware.RequestID 后自带 request_id 属性，处理器中记录的每条日志都能和请求关联起来；
+// 没有通过 SetLogger 设置时返回引擎的 Logger，引擎也没有设置时返回 slog.Default()
+func (c *Context) Logger() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	if c.zest != nil && c.zest.Logger != nil {
+		return c.zest.Logger
+	}
+	return slog.Default()
+}
+
+// SetLogger 设置当前请求的 logger，通常由中间件调用，如 c.SetLogger(c.Logger().With("user_id", uid))
+// 请求结束后随 Context 一起清空
+func (c *Context) SetLogger(l *slog.Logger) {
+	c.logger = l
+}
+
+// Deadline 返回请求 context 的截止时间，见 context.Context.Deadline
+// 与 Done、Err 一样每次都读取当前 c.Request 的 context，中间件通过 context.WithTimeout 替换请求后同样生效
+func (c *Context) Deadline() (time.Time, bool) {
+	return c.Request.Context().Deadline()
+}
+
+// Done 返回请求 context 的 Done channel，客户端断开或超时时关闭
+func (c *Context) Done() <-chan struct{} {
+	return c.Request.Context().Done()
+}
+
+// Err 返回请求 context 被取消的原因，未取消时返回 nil
+func (c *Context) Err() error {
+	return c.Request.Context().Err()
+}
+
 // Error 触发全局错误处理器
 // 这允许中间件在链中处理错误，而不是等到最外层
+// 每个请求只有第一次调用会生效：中间件调用 c.Error 后再返回同一个错误，引擎不会重复处理，之后的调用直接忽略
+// 第一次调用时响应已经提交（如流式响应中途出错），错误无法再写入响应，只记录到 c.Logger()
 func (c *Context) Error(err error) {
-	if c.zest != nil && c.zest.ErrHandler != nil {
-		c.zest.ErrHandler(c, err)
+	if err == nil || c.zest == nil || c.zest.ErrHandler == nil || c.handled {
+		return
+	}
+	c.handled = true
+	if c.response.Committed {
+		c.Logger().Warn("zest: error after response committed",
+			"method", c.Method, "path", c.Path, "error", err)
+		return
 	}
+	c.zest.ErrHandler(c, err)
 }
 
 // 路由参数，依赖 Go 1.22+ 的 r.PathValue
@@ -116,9 +224,50 @@ func (c *Context) Param(key string) string {
 	return c.Request.PathValue(key)
 }
 
+// RoutePattern 返回匹配到的路由模式（不含方法），如 "/users/{id}"
+// 适合作为指标和日志的 route 标签，避免按实际路径记录导致基数爆炸；没有匹配任何路由（404、405）时返回 ""
+func (c *Context) RoutePattern() string {
+	pattern := c.Request.Pattern
+	// 全局兜底 "/" 是唯一不带方法的模式
+	if pattern == "/" {
+		return ""
+	}
+	if _, path, found := strings.Cut(pattern, " "); found {
+		return strings.TrimSpace(path)
+	}
+	return pattern
+}
+
+// ParamDefault 返回指定名称的路由参数，不存在或为空时返回 fallback
+func (c *Context) ParamDefault(key, fallback string) string {
+	if v := c.Request.PathValue(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// ParamInt 将指定名称的路由参数解析为 int，如 /users/{id}
+// 参数不存在或不是合法的整数时返回 400 HTTPError
+func (c *Context) ParamInt(key string) (int, error) {
+	v, err := strconv.Atoi(c.Request.PathValue(key))
+	if err != nil {
+		return 0, NewHTTPError(http.StatusBadRequest, "invalid path param "+key).Wrap(err)
+	}
+	return v, nil
+}
+
 // Params Query参数
 func (c *Context) Query(key string) string {
-	return c.Request.URL.Query().Get(key)
+	return c.QueryParamsAll().Get(key)
+}
+
+// QueryParamsAll 返回所有查询参数，适合接收任意过滤条件的接口
+// 结果在同一个请求内缓存，多次调用不会重复解析；返回的 map 与 Context 共享，不要修改
+func (c *Context) QueryParamsAll() url.Values {
+	if c.query == nil {
+		c.query = c.Request.URL.Query()
+	}
+	return c.query
 }
 
 // Cookie 返回指定名称的 Cookie
@@ -136,20 +285,153 @@ func (c *Context) FormValue(name string) string {
 	return c.Request.FormValue(name)
 }
 
+// FormParams 返回所有表单参数，包含查询参数和请求体中的参数（Request.Form）
+// 解析结果缓存在 Request 上，多次调用不会重复解析；multipart 表单按引擎配置的内存上限解析
+func (c *Context) FormParams() (url.Values, error) {
+	if c.Request.Form == nil {
+		if _, err := c.postForm(); err != nil {
+			return nil, err
+		}
+	}
+	return c.Request.Form, nil
+}
+
+// FormDefault 返回请求体中指定名称的表单参数，不存在或为空时返回 fallback
+// 与 FormValue 不同，只读取请求体（Request.PostForm），不会被同名的查询参数覆盖
+func (c *Context) FormDefault(name, fallback string) string {
+	form, err := c.postForm()
+	if err != nil {
+		return fallback
+	}
+	if v := form.Get(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// FormArray 返回请求体中指定名称的所有表单参数，用于复选框等多值字段
+func (c *Context) FormArray(name string) []string {
+	form, err := c.postForm()
+	if err != nil {
+		return nil
+	}
+	return form[name]
+}
+
+// FormInt 将请求体中指定名称的表单参数解析为 int
+// 参数不存在或不是合法的整数时返回 400 HTTPError
+func (c *Context) FormInt(name string) (int, error) {
+	form, err := c.postForm()
+	if err != nil {
+		return 0, NewHTTPError(http.StatusBadRequest).Wrap(err)
+	}
+	v, err := strconv.Atoi(form.Get(name))
+	if err != nil {
+		return 0, NewHTTPError(http.StatusBadRequest, "invalid form value for "+name).Wrap(err)
+	}
+	return v, nil
+}
+
+// postForm 按需解析表单并返回请求体中的参数，解析结果缓存在 Request 上
+// multipart 表单按引擎配置的内存上限解析
+func (c *Context) postForm() (url.Values, error) {
+	if c.Request.PostForm == nil || c.Request.Form == nil {
+		if strings.HasPrefix(c.Request.Header.Get(HeaderContentType), MIMEMultipartForm) {
+			if err := c.Request.ParseMultipartForm(c.multipartMemoryLimit()); err != nil {
+				return nil, err
+			}
+		} else if err := c.Request.ParseForm(); err != nil {
+			return nil, err
+		}
+	}
+	return c.Request.PostForm, nil
+}
+
 // FormFile 返回指定名称的上传文件
 func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
-	_, fh, err := c.Request.FormFile(name)
-	return fh, err
+	// 先按引擎配置的内存上限解析，避免 Request.FormFile 使用标准库默认值
+	if c.Request.MultipartForm == nil {
+		if err := c.Request.ParseMultipartForm(c.multipartMemoryLimit()); err != nil {
+			return nil, err
+		}
+	}
+	f, fh, err := c.Request.FormFile(name)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return fh, nil
+}
+
+// MultipartReader 返回逐个读取 multipart 表单各部分的 *multipart.Reader，用于把大文件直接流式写入存储
+// 不会调用 ParseMultipartForm，也就不受 MultipartMemoryLimit 影响、不会生成临时文件；
+// 同一个请求中与 FormFile、MultipartForm、Bind 等解析表单的方法互斥
+// 请求不是 multipart/form-data 时返回 415 HTTPError，表单已经被解析过时返回 400 HTTPError
+func (c *Context) MultipartReader() (*multipart.Reader, error) {
+	mr, err := c.Request.MultipartReader()
+	if errors.Is(err, http.ErrNotMultipart) {
+		return nil, NewHTTPError(http.StatusUnsupportedMediaType, "request content type is not multipart/form-data").Wrap(err)
+	}
+	if err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest).Wrap(err)
+	}
+	return mr, nil
 }
 
 // MultipartForm 返回解析后的 MultipartForm
+// 内存上限由 Zest.MultipartMemoryLimit 控制，默认 32MB
 func (c *Context) MultipartForm() (*multipart.Form, error) {
-	err := c.Request.ParseMultipartForm(32 << 20) // 默认 32MB
+	err := c.Request.ParseMultipartForm(c.multipartMemoryLimit())
 	return c.Request.MultipartForm, err
 }
 
+// SaveUploadedFile 将上传的文件保存到 dst
+// dst 会先经过 filepath.Clean 处理，包含 .. 的路径会被拒绝
+func (c *Context) SaveUploadedFile(fh *multipart.FileHeader, dst string) error {
+	dst = filepath.Clean(dst)
+	for _, elem := range strings.Split(filepath.ToSlash(dst), "/") {
+		if elem == ".." {
+			return fmt.Errorf("zest: invalid upload destination %q", dst)
+		}
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
+
+func (c *Context) multipartMemoryLimit() int64 {
+	if c.zest != nil && c.zest.MultipartMemoryLimit > 0 {
+		return c.zest.MultipartMemoryLimit
+	}
+	return defaultMemory
+}
+
+// SetStatus 设置响应状态码，但不会立即写出响应头
+// 状态码在第一次写入响应体时随响应头一起写出，所以之后设置的响应头仍然有效，在写出之前也可以再次修改状态码
+// 没有写入响应体时（如 NoContent、Redirect），在所有中间件执行完之后写出；需要立即写出时调用 c.Response().WriteHeader
+// 响应已经提交（Committed）后调用不会有任何效果
 func (c *Context) SetStatus(statusCode int) {
-	c.response.WriteHeader(statusCode)
+	if c.response.Committed {
+		return
+	}
+	c.response.Status = statusCode
+	c.response.pending = true
 }
 
 func (c *Context) SetHeader(key string, value string) {
@@ -161,15 +443,28 @@ func (c *Context) Response() *Response {
 	return &c.response
 }
 
-// Writer 返回底层的 ResponseWriter
+// ResponseWriter 返回底层的 ResponseWriter
+// 已经通过 SetStatus 设置了状态码时会先写出响应头，直接写入底层 ResponseWriter 时状态码不会丢失，但之后设置的响应头不再生效
 func (c *Context) ResponseWriter() http.ResponseWriter {
+	c.response.writePending()
 	return c.response.ResponseWriter
 }
 
+// JSON 输出 JSON 响应，引擎开启 Debug 时自动缩进
+// 通过引擎的 JSONSerializer 编码，默认实现先编码到池化的缓冲区，成功后再写入响应
 func (c *Context) JSON(status int, data any) error {
+	if c.zest != nil && c.zest.Debug {
+		return c.JSONPretty(status, data, "  ")
+	}
 	c.SetHeader(HeaderContentType, MIMEApplicationJSON)
-	c.SetStatus(status)
-	return json.NewEncoder(&c.response).Encode(data)
+	return c.jsonSerializer().Serialize(c, data, status)
+}
+
+// JSONPretty 输出带缩进的 JSON 响应，indent 为每一级的缩进，如 "  " 或 "\t"
+// 会先把整个响应编码到内存中，适合调试，不适合很大的响应
+func (c *Context) JSONPretty(status int, data any, indent string) error {
+	c.SetHeader(HeaderContentType, MIMEApplicationJSON)
+	return c.jsonIndentSerializer().SerializeIndent(c, data, status, indent)
 }
 
 func (c *Context) String(status int, s string) error {
@@ -186,6 +481,76 @@ func (c *Context) HTML(status int, html string) error {
 	return err
 }
 
+// SSEvent 发送一条 Server-Sent Events 消息
+// 字符串数据原样写入，其他类型的数据会被 JSON 编码
+// 底层 ResponseWriter 不支持 http.Flusher 时返回 ErrFlushNotSupported
+func (c *Context) SSEvent(event string, data any) error {
+	if _, ok := c.response.ResponseWriter.(http.Flusher); !ok {
+		return ErrFlushNotSupported
+	}
+	// 客户端已断开，没必要继续写入
+	if err := c.Context().Err(); err != nil {
+		return err
+	}
+
+	if !c.response.Committed {
+		c.SetHeader(HeaderContentType, MIMETextEventStream)
+		c.SetHeader(HeaderCacheControl, "no-cache")
+		c.SetHeader(HeaderConnection, "keep-alive")
+		// 禁用 Nginx 等反向代理的缓冲
+		c.SetHeader("X-Accel-Buffering", "no")
+	}
+
+	var payload string
+	switch v := data.(type) {
+	case string:
+		payload = v
+	case []byte:
+		payload = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		payload = string(b)
+	}
+
+	var b strings.Builder
+	if event != "" {
+		b.WriteString("event: ")
+		b.WriteString(event)
+		b.WriteString("\n")
+	}
+	// 多行数据需要逐行加上 data: 前缀
+	for line := range strings.SplitSeq(payload, "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if _, err := c.response.WriteString(b.String()); err != nil {
+		return err
+	}
+	return c.Flush()
+}
+
+// Flush 将缓冲区中的数据立即发送给客户端
+// 配合 SSEvent 在循环中推送多条消息时使用
+func (c *Context) Flush() error {
+	flusher, ok := c.response.ResponseWriter.(http.Flusher)
+	if !ok {
+		return ErrFlushNotSupported
+	}
+	if !c.response.Committed {
+		c.response.WriteHeader(c.response.Status)
+	}
+	flusher.Flush()
+	return nil
+}
+
+// Set 在 Context 自带的 store 中保存值，供后续中间件和处理器通过 c.Get 读取
+// store 随 Context 放回对象池时清空，只在 zest 的处理链内可见；需要传给数据库驱动等只接收 context.Context 的库时使用 SetContext
 func (c *Context) Set(key string, val any) {
 	if c.store == nil {
 		c.store = make(Map)
@@ -197,6 +562,34 @@ func (c *Context) Get(key string) any {
 	return c.store[key]
 }
 
+// ContextKey SetContext 写入请求 context 时使用的 key 类型，不会与其他包的字符串 key 冲突
+// 拿不到 *Context 的代码可以通过 ctx.Value(zest.ContextKey("tenant")) 读取
+type ContextKey string
+
+// SetContext 把值写入 c.Request 的 context.Context，key 包装为 ContextKey
+// 与 c.Set 不同，值跟随请求的 context 传递：把 c.Request.Context() 或 c.Request 交给数据库驱动、gRPC 客户端等下游库后仍然可以读取
+// 每次调用都会用 WithContext 替换 c.Request，在中间件中调用时后续的处理器拿到的是新的 Request
+func (c *Context) SetContext(key string, val any) {
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ContextKey(key), val))
+}
+
+// Value 读取 SetContext 写入请求 context 的值，不存在时返回 nil
+func (c *Context) Value(key string) any {
+	return c.Request.Context().Value(ContextKey(key))
+}
+
+// Get 按类型读取 c.Set 存入的值，不存在或类型不匹配时 ok 为 false
+func Get[T any](c *Context, key string) (T, bool) {
+	v, ok := c.Get(key).(T)
+	return v, ok
+}
+
+// MustGet 按类型读取 c.Set 存入的值，不存在或类型不匹配时返回零值
+func MustGet[T any](c *Context, key string) T {
+	v, _ := Get[T](c, key)
+	return v
+}
+
 func (c *Context) NoContent(status int) error {
 	c.SetStatus(status)
 	return nil
@@ -242,6 +635,201 @@ func (c *Context) ClientIP() string {
 	return ""
 }
 
+// RealIP 返回客户端的真实 IP
+// 与 ClientIP 不同，只有当直接连接方属于 Zest.TrustedProxies 配置的可信代理时才会读取转发头，
+// X-Forwarded-For 从右向左遍历，跳过可信代理，返回第一个不可信的地址
+func (c *Context) RealIP() string {
+	remote := c.remoteIP()
+	if !c.fromTrustedProxy() {
+		return remote
+	}
+
+	if xff := c.Request.Header.Values(HeaderXForwardedFor); len(xff) > 0 {
+		ips := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(ips[i])
+			addr, err := netip.ParseAddr(ip)
+			if err != nil {
+				// 无法解析的地址不可信，停止遍历
+				break
+			}
+			if !c.zest.isTrustedProxy(addr) {
+				return ip
+			}
+		}
+	}
+
+	if ip := strings.TrimSpace(c.Request.Header.Get(HeaderXRealIP)); ip != "" {
+		if _, err := netip.ParseAddr(ip); err == nil {
+			return ip
+		}
+	}
+
+	return remote
+}
+
+// remoteIP 返回直接连接方的 IP，不考虑任何转发头
+func (c *Context) remoteIP() string {
+	remote := strings.TrimSpace(c.Request.RemoteAddr)
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	return remote
+}
+
+// fromTrustedProxy 判断直接连接方是否为 Zest.TrustedProxies 配置的可信代理
+// 只有可信代理设置的 X-Forwarded-* 头才能被采信
+func (c *Context) fromTrustedProxy() bool {
+	if c.zest == nil {
+		return false
+	}
+	if err := c.zest.loadTrustedProxies(); err != nil || len(c.zest.trustedProxies) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(c.remoteIP())
+	return err == nil && c.zest.isTrustedProxy(addr)
+}
+
+// IsTLS 判断客户端是否通过 HTTPS 访问，即 Scheme() == "https"
+// 部署在负责 TLS 终止的可信代理后面时同样返回 true
+func (c *Context) IsTLS() bool {
+	return c.Scheme() == "https"
+}
+
+// IsWebSocket 判断是否为 WebSocket 握手请求（Connection: Upgrade 且 Upgrade: websocket）
+func (c *Context) IsWebSocket() bool {
+	if !strings.EqualFold(strings.TrimSpace(c.Request.Header.Get(HeaderUpgrade)), "websocket") {
+		return false
+	}
+	for _, v := range c.Request.Header.Values(HeaderConnection) {
+		for token := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Scheme 返回客户端使用的协议（http 或 https）
+// 直接的 TLS 连接返回 https；请求来自可信代理时，依次检查 X-Forwarded-Proto、X-Forwarded-Protocol、X-Forwarded-Ssl、X-Url-Scheme
+// 没有配置可信代理时忽略这些请求头，防止客户端伪造
+func (c *Context) Scheme() string {
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	if !c.fromTrustedProxy() {
+		return "http"
+	}
+	if scheme := c.Request.Header.Get(HeaderXForwardedProto); scheme != "" {
+		return strings.ToLower(strings.TrimSpace(scheme))
+	}
+	if scheme := c.Request.Header.Get(HeaderXForwardedProtocol); scheme != "" {
+		return strings.ToLower(strings.TrimSpace(scheme))
+	}
+	if ssl := c.Request.Header.Get(HeaderXForwardedSsl); ssl == "on" {
+		return "https"
+	}
+	if scheme := c.Request.Header.Get(HeaderXUrlScheme); scheme != "" {
+		return strings.ToLower(strings.TrimSpace(scheme))
+	}
+	return "http"
+}
+
+// Host 返回客户端请求的主机名（可能包含端口）
+// 请求来自可信代理时优先使用 X-Forwarded-Host，有多个值时取第一个（离客户端最近的代理设置的）
+func (c *Context) Host() string {
+	if c.fromTrustedProxy() {
+		if host := c.Request.Header.Get(HeaderXForwardedHost); host != "" {
+			host, _, _ = strings.Cut(host, ",")
+			if host = strings.TrimSpace(host); host != "" {
+				return host
+			}
+		}
+	}
+	return c.Request.Host
+}
+
+// Accepts 根据请求的 Accept 头（包括 q 权重）从 offers 中选出最合适的类型
+// 支持 */* 和 text/* 这类通配符；没有 Accept 头时返回第一个 offer，都不匹配时返回 ""
+func (c *Context) Accepts(offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	header := c.Request.Header.Get(HeaderAccept)
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// acceptRange Accept 头中的一个媒体范围
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for part := range strings.SplitSeq(header, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+		// 容忍格式错误的条目，直接跳过
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			k, v, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(k) != "q" {
+				continue
+			}
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && f >= 0 && f <= 1 {
+				q = f
+			}
+		}
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality 返回 offer 在 ranges 中最具体匹配项的 q 值
+func acceptQuality(ranges []acceptRange, offer string) float64 {
+	base, _, _ := strings.Cut(offer, ";")
+	typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(base)), "/")
+	if !ok {
+		return 0
+	}
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
 // File 用于提供文件下载
 func (c *Context) File(filepath string) {
 	// http.ServeFile 是 Go 标准库提供的强大函数：
@@ -249,7 +837,18 @@ func (c *Context) File(filepath string) {
 	// 2. 处理 Last-Modified 和 If-Modified-Since (支持浏览器缓存！)
 	// 3. 支持 Range 请求 (视频拖动播放、断点续传)
 	// 4. 安全地读取文件流写入 Response
-	http.ServeFile(c.response.ResponseWriter, c.Request, filepath)
+	// 先写出 SetStatus 设置的状态码，ServeFile 随后的 WriteHeader(200) 会被忽略
+	c.response.writePending()
+	http.ServeFile(c.Response(), c.Request, filepath)
+}
+
+// ServeContent 使用 http.ServeContent 输出 content，适合生成后可以缓存的内容（如导出的报表、缩略图）
+// 支持 Range 请求和条件请求：modtime 不为零时处理 If-Modified-Since；事先通过 SetHeader 设置 ETag 时处理 If-None-Match，
+// 满足条件时返回 304；name 只用于按扩展名推断 Content-Type，已经设置 Content-Type 时不会覆盖
+// 响应由 http.ServeContent 写出，错误（如 416）也由它处理，所以总是返回 nil
+func (c *Context) ServeContent(name string, modtime time.Time, content io.ReadSeeker) error {
+	http.ServeContent(c.Response(), c.Request, name, modtime, content)
+	return nil
 }
 
 // Attachment 用于提供文件下载，并指定下载文件名
@@ -259,5 +858,6 @@ func (c *Context) Attachment(file string, name string) {
 	// filename=... 指定了用户保存时默认显示的文件名
 	c.SetHeader("Content-Disposition", `attachment; filename*=UTF-8''`+url.PathEscape(name))
 	// 同样复用 ServeFile 来处理文件流传输
-	http.ServeFile(c.response.ResponseWriter, c.Request, file)
+	c.response.writePending()
+	http.ServeFile(c.Response(), c.Request, file)
 }
@@ -0,0 +1,386 @@
+package zest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestContextPoolIsolation(t *testing.T) {
+	z := New()
+	// 每个请求读取到的都必须是自己的值：进入处理器时 store 为空，Param 和 Request 来自当前请求
+	check := func(c *Context) error {
+		if v := c.Get("id"); v != nil {
+			return c.String(http.StatusInternalServerError, fmt.Sprintf("stale store value %v", v))
+		}
+		if c.Request.URL.Path != c.Path {
+			return c.String(http.StatusInternalServerError, "stale request "+c.Request.URL.Path)
+		}
+		c.Set("id", c.Param("id"))
+		return c.String(http.StatusOK, fmt.Sprintf("%s|%v", c.Param("id"), c.Get("id")))
+	}
+	z.GET("/items/{id}", check)
+	z.GET("/plain", check)
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/items/1", "1|1"},
+		{"/items/2", "2|2"},
+		{"/items/abc", "abc|abc"},
+		{"/plain", "|"},
+	}
+
+	const rounds = 200
+	var wg sync.WaitGroup
+	errs := make(chan string, rounds*len(tests))
+	for range rounds {
+		for _, tt := range tests {
+			wg.Go(func() {
+				rec := z.TestRequest(http.MethodGet, tt.target, nil)
+				if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+					errs <- fmt.Sprintf("GET %s = %d %q, want 200 %q", tt.target, rec.Code, rec.Body.String(), tt.want)
+				}
+			})
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for msg := range errs {
+		t.Error(msg)
+	}
+}
+
+func TestParamTyped(t *testing.T) {
+	z := New()
+	z.GET("/users/{id}", func(c *Context) error {
+		id, err := c.ParamInt("id")
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, fmt.Sprintf("%d %s %s", id, c.Param("id"), c.ParamDefault("tab", "profile")))
+	})
+	z.GET("/pages/{name...}", func(c *Context) error {
+		return c.String(http.StatusOK, c.ParamDefault("name", "index"))
+	})
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"int", "/users/42", http.StatusOK, "42 42 profile"},
+		{"negative int", "/users/-7", http.StatusOK, "-7 -7 profile"},
+		{"not a number", "/users/abc", http.StatusBadRequest, `{"error":"invalid path param id"}`},
+		{"overflow", "/users/99999999999999999999", http.StatusBadRequest, `{"error":"invalid path param id"}`},
+		{"default for empty param", "/pages/", http.StatusOK, "index"},
+		{"param present", "/pages/about/team", http.StatusOK, "about/team"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := z.TestRequest(http.MethodGet, tt.target, nil)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+// headerCounter 统计底层 ResponseWriter 的 WriteHeader 被调用的次数
+type headerCounter struct {
+	*httptest.ResponseRecorder
+	headers int
+}
+
+func (w *headerCounter) WriteHeader(code int) {
+	w.headers++
+	w.ResponseRecorder.WriteHeader(code)
+}
+
+func TestSetStatusDeferred(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(file, []byte("report"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		bufferSize int
+		handler    HandlerFunc
+		wantStatus int
+		wantHeader bool
+	}{
+		{
+			name:       "set status then return",
+			handler:    func(c *Context) error { c.SetStatus(http.StatusCreated); return nil },
+			wantStatus: http.StatusCreated,
+			wantHeader: true,
+		},
+		{
+			name: "status changed before write",
+			handler: func(c *Context) error {
+				c.SetStatus(http.StatusCreated)
+				c.SetStatus(http.StatusAccepted)
+				return nil
+			},
+			wantStatus: http.StatusAccepted,
+			wantHeader: true,
+		},
+		{
+			name: "error handler overrides status",
+			handler: func(c *Context) error {
+				c.SetStatus(http.StatusCreated)
+				return NewHTTPError(http.StatusConflict)
+			},
+			// 路由返回的错误在路由内部交给错误处理器，响应体在外层中间件返回之前已经写出
+			wantStatus: http.StatusConflict,
+			wantHeader: false,
+		},
+		{
+			name:       "no content",
+			handler:    func(c *Context) error { return c.NoContent(http.StatusNoContent) },
+			wantStatus: http.StatusNoContent,
+			wantHeader: true,
+		},
+		{
+			name:       "json commits the response",
+			handler:    func(c *Context) error { return c.JSON(http.StatusCreated, Map{"ok": true}) },
+			wantStatus: http.StatusCreated,
+			wantHeader: false,
+		},
+		{
+			name:       "json with response buffer",
+			bufferSize: 4096,
+			handler:    func(c *Context) error { return c.JSON(http.StatusCreated, Map{"ok": true}) },
+			wantStatus: http.StatusCreated,
+			wantHeader: true,
+		},
+		{
+			name: "set status then file",
+			handler: func(c *Context) error {
+				c.SetStatus(http.StatusAccepted)
+				c.File(file)
+				return nil
+			},
+			wantStatus: http.StatusAccepted,
+			wantHeader: false,
+		},
+		{
+			name: "set status then attachment",
+			handler: func(c *Context) error {
+				c.SetStatus(http.StatusAccepted)
+				c.Attachment(file, "report.txt")
+				return nil
+			},
+			wantStatus: http.StatusAccepted,
+			wantHeader: false,
+		},
+		{
+			name:       "file without status",
+			handler:    func(c *Context) error { c.File(file); return nil },
+			wantStatus: http.StatusOK,
+			wantHeader: false,
+		},
+		{
+			name: "set status then raw write",
+			handler: func(c *Context) error {
+				c.SetStatus(http.StatusAccepted)
+				_, err := c.ResponseWriter().Write([]byte("raw"))
+				return err
+			},
+			wantStatus: http.StatusAccepted,
+			wantHeader: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := New()
+			z.ResponseBufferSize = tt.bufferSize
+			// 处理器返回之后才设置响应头，状态码还没有写出时仍然有效
+			z.Use(func(next HandlerFunc) HandlerFunc {
+				return func(c *Context) error {
+					err := next(c)
+					c.SetHeader("X-After", "1")
+					return err
+				}
+			})
+			z.GET("/", tt.handler)
+
+			w := &headerCounter{ResponseRecorder: httptest.NewRecorder()}
+			z.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			rec := w.ResponseRecorder
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			// 状态码只写出一次，不会出现 superfluous WriteHeader
+			if w.headers != 1 {
+				t.Errorf("WriteHeader calls = %d, want 1", w.headers)
+			}
+			// Result 中的响应头是写出状态码时的快照，与客户端实际收到的一致
+			if got := rec.Result().Header.Get("X-After") == "1"; got != tt.wantHeader {
+				t.Errorf("X-After present = %v, want %v", got, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		remote  string
+		xff     []string
+		xRealIP string
+		want    string
+	}{
+		{"no proxies ignores headers", nil, "203.0.113.9:1234", []string{"1.1.1.1"}, "2.2.2.2", "203.0.113.9"},
+		{"untrusted remote ignores headers", []string{"10.0.0.0/8"}, "203.0.113.9:1234", []string{"1.1.1.1"}, "", "203.0.113.9"},
+		{"trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.2:1234", []string{"1.1.1.1"}, "", "1.1.1.1"},
+		{"spoofed left entries skipped", []string{"10.0.0.0/8"}, "10.0.0.2:1234", []string{"6.6.6.6, 1.1.1.1, 10.0.0.3"}, "", "1.1.1.1"},
+		{"multiple header lines", []string{"10.0.0.0/8"}, "10.0.0.2:1234", []string{"6.6.6.6", "1.1.1.1, 10.0.0.3"}, "", "1.1.1.1"},
+		{"single ip proxy", []string{"192.0.2.1"}, "192.0.2.1:80", []string{"1.1.1.1"}, "", "1.1.1.1"},
+		{"invalid entry stops the walk", []string{"10.0.0.0/8"}, "10.0.0.2:1234", []string{"1.1.1.1, bogus"}, "", "10.0.0.2"},
+		{"all entries trusted", []string{"10.0.0.0/8"}, "10.0.0.2:1234", []string{"10.0.0.4, 10.0.0.3"}, "", "10.0.0.2"},
+		{"x-real-ip fallback", []string{"10.0.0.0/8"}, "10.0.0.2:1234", nil, "1.1.1.1", "1.1.1.1"},
+		{"invalid x-real-ip", []string{"10.0.0.0/8"}, "10.0.0.2:1234", nil, "bogus", "10.0.0.2"},
+		{"ipv6 proxy", []string{"fd00::/8"}, "[fd00::1]:443", []string{"2001:db8::1"}, "", "2001:db8::1"},
+		{"ipv4-mapped remote", []string{"10.0.0.0/8"}, "[::ffff:10.0.0.2]:1234", []string{"1.1.1.1"}, "", "1.1.1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := New()
+			if err := z.SetTrustedProxies(tt.trusted...); err != nil {
+				t.Fatal(err)
+			}
+			z.GET("/", func(c *Context) error { return c.String(http.StatusOK, c.RealIP()) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				req.Header.Add(HeaderXForwardedFor, v)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set(HeaderXRealIP, tt.xRealIP)
+			}
+			rec := z.Test(req)
+
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("RealIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTrustedProxiesInvalid(t *testing.T) {
+	for _, p := range []string{"10.0.0.0/33", "not-an-ip", ""} {
+		if err := New().SetTrustedProxies(p); err == nil {
+			t.Errorf("SetTrustedProxies(%q) error = nil, want error", p)
+		}
+	}
+}
+
+func TestTrustedProxiesField(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		want    string
+	}{
+		{"trusted", []string{"192.0.2.1", " 10.0.0.0/8 "}, "1.1.1.1"},
+		{"empty", nil, "10.0.0.2"},
+		// 格式错误时不信任任何代理，而不是只忽略错误的那一项
+		{"invalid trusts nothing", []string{"10.0.0.0/8", "bogus"}, "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := New()
+			z.TrustedProxies = tt.trusted
+			z.GET("/", func(c *Context) error { return c.String(http.StatusOK, c.RealIP()) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.2:1234"
+			req.Header.Set(HeaderXForwardedFor, "1.1.1.1")
+			if got := z.Test(req).Body.String(); got != tt.want {
+				t.Errorf("RealIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrustedProxiesInvalidRun(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := New()
+	z.HideBanner = true
+	z.TrustedProxies = []string{"10.0.0.0/33"}
+
+	err = z.RunListener(ln)
+	if err == nil || !strings.Contains(err.Error(), "invalid trusted proxy") {
+		t.Errorf("RunListener error = %v, want invalid trusted proxy", err)
+	}
+}
+
+func TestRoutePattern(t *testing.T) {
+	var outer string
+	z := New()
+	// 全局中间件在路由执行之后同样能读到匹配的模式
+	z.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			err := next(c)
+			outer = c.RoutePattern()
+			return err
+		}
+	})
+	pattern := func(c *Context) error { return c.String(http.StatusOK, c.RoutePattern()) }
+	z.GET("/users/{id}", pattern)
+	z.GET("/files/{path...}", pattern)
+	z.Group("/api/v1").GET("/orders/{id}", pattern)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		want       string
+	}{
+		{"param route", http.MethodGet, "/users/42", http.StatusOK, "/users/{id}"},
+		{"wildcard route", http.MethodGet, "/files/a/b.txt", http.StatusOK, "/files/{path...}"},
+		{"group route", http.MethodGet, "/api/v1/orders/7", http.StatusOK, "/api/v1/orders/{id}"},
+		{"not found", http.MethodGet, "/missing", http.StatusNotFound, ""},
+		{"method not allowed", http.MethodPost, "/users/42", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outer = "unset"
+			rec := z.TestRequest(tt.method, tt.target, nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != tt.want {
+				t.Errorf("RoutePattern in handler = %q, want %q", rec.Body.String(), tt.want)
+			}
+			if outer != tt.want {
+				t.Errorf("RoutePattern in middleware = %q, want %q", outer, tt.want)
+			}
+		})
+	}
+}
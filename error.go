@@ -1,13 +1,26 @@
 package zest
 
 import (
+	"errors"
+	"fmt"
+	"html/template"
 	"net/http"
+	"strings"
 )
 
+// ErrFlushNotSupported 底层 ResponseWriter 不支持 http.Flusher
+var ErrFlushNotSupported = errors.New("zest: response writer does not support flushing")
+
+// HTTPError 携带 HTTP 状态码的错误
 type HTTPError struct {
-	Code    int
-	Message string
-	err     error
+	// Code HTTP 状态码
+	Code int
+	// Message 返回给客户端的错误信息，可以是字符串或任意可 JSON 编码的值
+	Message any
+	// Internal 内部错误，不会返回给客户端，可通过 errors.Is/errors.As 访问
+	Internal error
+	// Headers 写入错误响应前设置的响应头，如 401 的 WWW-Authenticate、429 的 Retry-After
+	Headers map[string]string
 }
 
 func DefaultErrHandlerFunc(c *Context, err error) {
@@ -17,13 +30,23 @@ func DefaultErrHandlerFunc(c *Context, err error) {
 	}
 
 	var status int
-	var errMsg string
-	if he, ok := err.(*HTTPError); ok {
+	var message any
+	var he *HTTPError
+	if errors.As(err, &he) {
 		status = he.Code
-		errMsg = he.Message
+		message = he.Message
+		for k, v := range he.Headers {
+			c.SetHeader(k, v)
+		}
 	} else {
+		// 未映射的错误统一返回 500
 		status = http.StatusInternalServerError
-		errMsg = err.Error()
+		if c.zest != nil {
+			if code, ok := c.zest.mappedStatus(err); ok {
+				status = code
+			}
+		}
+		message = err.Error()
 	}
 
 	// HEAD请求不需要返回响应
@@ -32,11 +55,42 @@ func DefaultErrHandlerFunc(c *Context, err error) {
 		return
 	}
 
-	// 返回错误响应
-	c.JSON(status, Map{"error": errMsg})
+	// 根据 Accept 选择响应格式，默认 JSON
+	// 写入失败时只能放弃，不能再交给错误处理器，否则会陷入循环
+	switch c.Accepts(MIMEApplicationJSON, MIMETextHTML, MIMETextPlain) {
+	case MIMETextHTML:
+		var b strings.Builder
+		data := ErrorPageData{Code: status, StatusText: http.StatusText(status), Message: fmt.Sprint(message)}
+		if err := ErrorPageTemplate.Execute(&b, data); err == nil {
+			_ = c.HTML(status, b.String())
+			return
+		}
+		_ = c.String(status, fmt.Sprint(message))
+	case MIMETextPlain:
+		_ = c.String(status, fmt.Sprint(message))
+	default:
+		_ = c.JSON(status, Map{"error": message})
+	}
 }
 
-func NewHTTPError(code int, message ...string) *HTTPError {
+// ErrorPageData ErrorPageTemplate 的模板数据
+type ErrorPageData struct {
+	Code       int
+	StatusText string
+	Message    string
+}
+
+// ErrorPageTemplate DefaultErrHandlerFunc 对浏览器请求（Accept: text/html）返回的错误页模板
+// 可以替换成自定义模板，模板数据为 ErrorPageData
+var ErrorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>{{ .Code }} {{ .StatusText }}</title></head>
+<body><h1>{{ .Code }} {{ .StatusText }}</h1><p>{{ .Message }}</p></body>
+</html>
+`))
+
+// NewHTTPError 创建 HTTPError，不传 message 时使用状态码对应的标准文本
+func NewHTTPError(code int, message ...any) *HTTPError {
 	if len(message) == 0 {
 		return &HTTPError{Code: code, Message: http.StatusText(code)}
 	}
@@ -44,17 +98,35 @@ func NewHTTPError(code int, message ...string) *HTTPError {
 }
 
 func (e *HTTPError) Error() string {
-	if e.Message == "" {
+	switch msg := e.Message.(type) {
+	case nil:
 		return http.StatusText(e.Code)
+	case string:
+		if msg == "" {
+			return http.StatusText(e.Code)
+		}
+		return msg
+	default:
+		return fmt.Sprint(msg)
 	}
-	return e.Message
 }
 
+// Wrap 设置内部错误
 func (e *HTTPError) Wrap(err error) *HTTPError {
-	e.err = err
+	e.Internal = err
+	return e
+}
+
+// WithHeader 为错误响应添加响应头，可链式调用
+func (e *HTTPError) WithHeader(key, value string) *HTTPError {
+	if e.Headers == nil {
+		e.Headers = make(map[string]string)
+	}
+	e.Headers[key] = value
 	return e
 }
 
+// Unwrap 返回内部错误，使 errors.Is/errors.As 能够穿透 HTTPError
 func (e *HTTPError) Unwrap() error {
-	return e.err
+	return e.Internal
 }
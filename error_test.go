@@ -0,0 +1,82 @@
+package zest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHTTPErrorUnwrap(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "a.txt", Err: fs.ErrNotExist}
+
+	tests := []struct {
+		name      string
+		err       error
+		wantCode  int
+		wantIs    error
+		wantInner bool
+	}{
+		{"plain", NewHTTPError(http.StatusNotFound), http.StatusNotFound, nil, false},
+		{"wrapped internal", NewHTTPError(http.StatusNotFound).Wrap(pathErr), http.StatusNotFound, fs.ErrNotExist, true},
+		{"wrapped by fmt", fmt.Errorf("load: %w", NewHTTPError(http.StatusForbidden).Wrap(pathErr)), http.StatusForbidden, fs.ErrNotExist, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var he *HTTPError
+			if !errors.As(tt.err, &he) {
+				t.Fatalf("errors.As(%v, *HTTPError) = false", tt.err)
+			}
+			if he.Code != tt.wantCode {
+				t.Errorf("Code = %d, want %d", he.Code, tt.wantCode)
+			}
+			if tt.wantIs != nil && !errors.Is(tt.err, tt.wantIs) {
+				t.Errorf("errors.Is(%v, %v) = false", tt.err, tt.wantIs)
+			}
+			var pe *fs.PathError
+			if got := errors.As(tt.err, &pe); got != tt.wantInner {
+				t.Errorf("errors.As(*fs.PathError) = %v, want %v", got, tt.wantInner)
+			}
+		})
+	}
+}
+
+func TestDefaultErrHandlerFunc(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+		wantHeader string
+	}{
+		{"http error", NewHTTPError(http.StatusBadRequest, "bad id"), http.StatusBadRequest, `{"error":"bad id"}`, ""},
+		{"default message", NewHTTPError(http.StatusNotFound), http.StatusNotFound, `{"error":"Not Found"}`, ""},
+		{"structured message", NewHTTPError(http.StatusConflict, Map{"field": "name"}), http.StatusConflict, `{"error":{"field":"name"}}`, ""},
+		{"wrapped http error", fmt.Errorf("handler: %w", NewHTTPError(http.StatusForbidden)), http.StatusForbidden, `{"error":"Forbidden"}`, ""},
+		{"internal not exposed", NewHTTPError(http.StatusUnauthorized).Wrap(errors.New("token expired")), http.StatusUnauthorized, `{"error":"Unauthorized"}`, ""},
+		{"headers", NewHTTPError(http.StatusTooManyRequests).WithHeader("Retry-After", "3"), http.StatusTooManyRequests, `{"error":"Too Many Requests"}`, "3"},
+		{"plain error", errors.New("boom"), http.StatusInternalServerError, `{"error":"boom"}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := New()
+			z.GET("/", func(c *Context) error { return tt.err })
+
+			rec := z.TestRequest(http.MethodGet, "/", nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantHeader {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
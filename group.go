@@ -1,44 +1,77 @@
 package zest
 
 import (
+	"io/fs"
 	"net/http"
 	"strings"
 )
 
 // Group 路由分组
+// 请求依次经过全局中间件、各级父分组中间件、当前分组中间件和路由中间件，每个只执行一次
+// 中间件在注册路由时确定，之后再调用 Use 只影响后续注册的路由
 type Group struct {
-	prefix      string
+	prefix string
+	// middlewares 只包含当前分组自己的中间件，父分组的中间件通过 parent 获取
 	middlewares []MiddlewareFunc
+	parent      *Group
 	zest        *Zest
 }
 
-func (g *Group) handle(method string, pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
+func (g *Group) handle(method string, pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
 	// 拼接路由前缀，确保路径规范化
 	fullPattern := joinPath(g.prefix, pattern)
 
 	// 合并分组中间件和路由中间件
-	finalMws := append(g.middlewares, mws...)
+	finalMws := mergeMiddlewares(g.chain(), mws)
 
-	g.zest.handle(method, fullPattern, handler, finalMws...)
+	return g.zest.handle(method, fullPattern, handler, finalMws...)
 }
 
+// chain 返回从最外层父分组到当前分组的所有中间件，每次返回新切片
+func (g *Group) chain() []MiddlewareFunc {
+	if g.parent == nil {
+		return mergeMiddlewares(nil, g.middlewares)
+	}
+	return mergeMiddlewares(g.parent.chain(), g.middlewares)
+}
+
+// joinPath 拼接分组前缀和路由模式，段之间只保留一个 /
+// pattern 为空时得到分组根路径（"/api"），pattern 末尾的 / 会保留（"/api/" 匹配子路径）
 func joinPath(prefix, pattern string) string {
 	if prefix == "" {
 		return pattern
 	}
 	if pattern == "" {
-		return prefix
+		return cleanPattern(strings.TrimRight(prefix, "/"))
 	}
 	// 手动拼接，避免 url.JoinPath 转义 {} 等特殊字符
-	final := strings.TrimRight(prefix, "/") + "/" + strings.TrimLeft(pattern, "/")
-	return final
+	return cleanPattern(prefix + "/" + pattern)
+}
+
+// cleanPattern 规范化路由模式的路径：空模式视为 "/"，补全开头的 /，合并连续的 /
+// 包含主机名的模式（如 "example.com/users"）原样返回
+func cleanPattern(pattern string) string {
+	if pattern == "" {
+		return "/"
+	}
+	if !strings.HasPrefix(pattern, "/") {
+		if strings.Contains(pattern, "/") {
+			return pattern
+		}
+		pattern = "/" + pattern
+	}
+	for strings.Contains(pattern, "//") {
+		pattern = strings.ReplaceAll(pattern, "//", "/")
+	}
+	return pattern
 }
 
 // Group 创建嵌套分组
 func (g *Group) Group(prefix string, mws ...MiddlewareFunc) *Group {
 	return &Group{
-		prefix:      g.prefix + prefix,
-		middlewares: append(g.middlewares, mws...),
+		prefix:      joinPath(g.prefix, prefix),
+		middlewares: mergeMiddlewares(nil, mws),
+		parent:      g,
 		zest:        g.zest,
 	}
 }
@@ -49,33 +82,58 @@ func (g *Group) Use(mws ...MiddlewareFunc) {
 }
 
 // Methods
-func (g *Group) GET(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	g.handle(http.MethodGet, pattern, handler, mws...)
+func (g *Group) GET(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.handle(http.MethodGet, pattern, handler, mws...)
 }
 
-func (g *Group) POST(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	g.handle(http.MethodPost, pattern, handler, mws...)
+func (g *Group) POST(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.handle(http.MethodPost, pattern, handler, mws...)
 }
 
-func (g *Group) PUT(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	g.handle(http.MethodPut, pattern, handler, mws...)
+func (g *Group) PUT(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.handle(http.MethodPut, pattern, handler, mws...)
 }
 
-func (g *Group) PATCH(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	g.handle(http.MethodPatch, pattern, handler, mws...)
+func (g *Group) PATCH(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.handle(http.MethodPatch, pattern, handler, mws...)
 }
 
-func (g *Group) DELETE(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	g.handle(http.MethodDelete, pattern, handler, mws...)
+func (g *Group) DELETE(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.handle(http.MethodDelete, pattern, handler, mws...)
+}
+
+func (g *Group) OPTIONS(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.handle(http.MethodOptions, pattern, handler, mws...)
+}
+
+// HEAD 在分组内显式注册 HEAD 路由，优先于 AutoHead 对 GET 路由的自动响应
+func (g *Group) HEAD(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.handle(http.MethodHead, pattern, handler, mws...)
+}
+
+// Any 在分组内为 GET、POST、PUT、PATCH、DELETE、OPTIONS、HEAD 注册同一个处理函数
+func (g *Group) Any(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) []*Route {
+	return g.Match(anyMethods, pattern, handler, mws...)
+}
+
+// Match 在分组内为指定的多个方法注册同一个处理函数
+func (g *Group) Match(methods []string, pattern string, handler HandlerFunc, mws ...MiddlewareFunc) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, g.handle(method, pattern, handler, mws...))
+	}
+	return routes
 }
 
-// Static 在分组内提供静态文件服务
+// Static 在分组内提供静态文件服务，分组的中间件（鉴权、缓存等）同样作用于静态文件
+// prefix 相对于分组前缀，如分组 /tenant/{id} 下的 Static("/assets", root) 对应 /tenant/{id}/assets/
 func (g *Group) Static(prefix, root string) {
-	// 拼接分组前缀
-	fullPrefix := joinPath(g.prefix, prefix)
-	g.zest.Static(fullPrefix, root)
+	prefix = staticPrefix(prefix)
+	g.GET(prefix+"{path...}", g.zest.staticHandler(root))
 }
 
-func (g *Group) OPTIONS(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	g.handle(http.MethodOptions, pattern, handler, mws...)
+// StaticFS 在分组内使用 fs.FS 提供静态文件服务，见 Group.Static 和 Zest.StaticFS
+func (g *Group) StaticFS(prefix string, fsys fs.FS) {
+	prefix = staticPrefix(prefix)
+	g.GET(prefix+"{path...}", g.zest.staticFSHandler(fsys))
 }
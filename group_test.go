@@ -0,0 +1,91 @@
+package zest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGroupJoinPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		prefix      string
+		pattern     string
+		nested      string
+		wantPattern string
+	}{
+		{"plain", "/api", "/users", "", "/api/users"},
+		{"prefix trailing slash", "/api/", "/users", "", "/api/users"},
+		{"pattern without slash", "/api", "users", "", "/api/users"},
+		{"both slashes", "/api/", "users", "", "/api/users"},
+		{"double slashes", "/api//", "//users", "", "/api/users"},
+		{"host prefix kept", "example.com", "users", "", "example.com/users"},
+		{"empty pattern is group root", "/api/", "", "", "/api"},
+		{"trailing slash pattern kept", "/api", "/users/", "", "/api/users/"},
+		{"nested", "/api/", "users", "/v1/", "/api/v1/users"},
+		{"nested without slashes", "/api", "users", "v1", "/api/v1/users"},
+		{"nested root", "/api", "", "/v1/", "/api/v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := New()
+			g := z.Group(tt.prefix)
+			if tt.nested != "" {
+				g = g.Group(tt.nested)
+			}
+			route := g.GET(tt.pattern, func(c *Context) error { return c.String(http.StatusOK, "ok") })
+
+			if route.Pattern != tt.wantPattern {
+				t.Errorf("pattern = %q, want %q", route.Pattern, tt.wantPattern)
+			}
+			target := tt.wantPattern
+			if host, p, ok := strings.Cut(target, "/"); ok && host != "" {
+				target = "http://" + host + "/" + p
+			}
+			if rec := z.TestRequest(http.MethodGet, target, nil); rec.Code != http.StatusOK {
+				t.Errorf("GET %s status = %d, want 200", target, rec.Code)
+			}
+		})
+	}
+}
+
+func TestNestedGroupMiddlewareOrder(t *testing.T) {
+	var trace []string
+	z := New()
+	z.Use(record(&trace, "global"))
+
+	a := z.Group("/a", record(&trace, "a"))
+	b := a.Group("/b", record(&trace, "b"))
+	// 子分组的 Use 只追加到自己的中间件，父分组的中间件不会重复执行
+	b.Use(record(&trace, "b2"))
+	sibling := a.Group("/s", record(&trace, "s"))
+
+	ok := func(c *Context) error { return c.NoContent(http.StatusOK) }
+	a.GET("/x", ok, record(&trace, "route"))
+	b.GET("/x", ok, record(&trace, "route"))
+	sibling.GET("/x", ok)
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/a/x", []string{"global", "a", "route"}},
+		{"/a/b/x", []string{"global", "a", "b", "b2", "route"}},
+		{"/a/s/x", []string{"global", "a", "s"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			trace = nil
+			rec := z.TestRequest(http.MethodGet, tt.target, nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			if !slices.Equal(trace, tt.want) {
+				t.Errorf("middlewares = [%s], want [%s]", strings.Join(trace, " "), strings.Join(tt.want, " "))
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package zest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// JSONSerializer JSON 编解码接口，c.JSON 和 c.Bind 都通过它处理 JSON
+// 可以替换成 sonic、jsoniter 等更快的实现：z.JSONSerializer = mySerializer{}
+type JSONSerializer interface {
+	// Serialize 将 data 编码后写入响应，status 为响应状态码
+	// Content-Type 已经由调用方设置；实现可以先编码，成功后再写入状态码
+	Serialize(c *Context, data any, status int) error
+	// Deserialize 将请求体解码到 dst
+	Deserialize(c *Context, dst any) error
+}
+
+// JSONIndentSerializer 可选接口，JSONSerializer 同时实现它时 c.JSONPretty 通过它输出缩进的 JSON
+// 没有实现时 c.JSONPretty 使用 encoding/json
+type JSONIndentSerializer interface {
+	SerializeIndent(c *Context, data any, status int, indent string) error
+}
+
+// DefaultJSONSerializer 基于标准库 encoding/json 的 JSONSerializer
+type DefaultJSONSerializer struct{}
+
+// maxPooledJSONBuffer 放回池中的缓冲区容量上限，超过的缓冲区直接丢弃，避免个别大响应长期占用内存
+const maxPooledJSONBuffer = 64 << 10
+
+// jsonBuffer 编码缓冲区和绑定在它上面的 Encoder，一起复用可以省掉每次创建 Encoder 的分配
+type jsonBuffer struct {
+	bytes.Buffer
+	enc *json.Encoder
+}
+
+// jsonBufferPool 复用 Serialize 的编码缓冲区
+var jsonBufferPool = sync.Pool{
+	New: func() any {
+		b := &jsonBuffer{}
+		b.enc = json.NewEncoder(&b.Buffer)
+		return b
+	},
+}
+
+// Serialize 实现 JSONSerializer
+// 先编码到池化的缓冲区，成功后再设置 Content-Length 并写入响应，编码失败时不会写出半个响应
+func (DefaultJSONSerializer) Serialize(c *Context, data any, status int) error {
+	buf := jsonBufferPool.Get().(*jsonBuffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledJSONBuffer {
+			jsonBufferPool.Put(buf)
+		}
+	}()
+
+	if err := buf.enc.Encode(data); err != nil {
+		return err
+	}
+	c.SetHeader(HeaderContentLength, strconv.Itoa(buf.Len()))
+	c.SetStatus(status)
+	_, err := c.Response().Write(buf.Bytes())
+	return err
+}
+
+// SerializeIndent 实现 JSONIndentSerializer
+// 先完整编码再写入响应，编码失败时不会写出半个响应
+func (DefaultJSONSerializer) SerializeIndent(c *Context, data any, status int, indent string) error {
+	b, err := json.MarshalIndent(data, "", indent)
+	if err != nil {
+		return err
+	}
+	c.SetStatus(status)
+	_, err = c.Response().Write(append(b, '\n'))
+	return err
+}
+
+// Deserialize 实现 JSONSerializer
+// 引擎开启 DisallowUnknownFields 或通过 c.BindStrict 调用时，拒绝 dst 中不存在的字段
+func (DefaultJSONSerializer) Deserialize(c *Context, dst any) error {
+	dec := json.NewDecoder(c.Request.Body)
+	if c.disallowUnknownFields() {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(dst)
+}
+
+// disallowUnknownFields 当前绑定是否应拒绝 JSON 中的未知字段
+func (c *Context) disallowUnknownFields() bool {
+	return c.strictJSON || (c.zest != nil && c.zest.DisallowUnknownFields)
+}
+
+// jsonIndentSerializer 返回支持缩进的 JSONIndentSerializer，引擎的 JSONSerializer 不支持时使用 DefaultJSONSerializer
+func (c *Context) jsonIndentSerializer() JSONIndentSerializer {
+	if s, ok := c.jsonSerializer().(JSONIndentSerializer); ok {
+		return s
+	}
+	return DefaultJSONSerializer{}
+}
+
+// jsonSerializer 返回引擎配置的 JSONSerializer，没有配置时使用 DefaultJSONSerializer
+func (c *Context) jsonSerializer() JSONSerializer {
+	if c.zest != nil && c.zest.JSONSerializer != nil {
+		return c.zest.JSONSerializer
+	}
+	return DefaultJSONSerializer{}
+}
+
+// jsonpCallbackRegex 合法的 JSONP 回调名：JS 标识符或以 . 连接的标识符路径，如 "cb"、"jQuery123.handle"
+var jsonpCallbackRegex = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*(\.[a-zA-Z_$][a-zA-Z0-9_$]*)*$`)
+
+// JSONP 输出 JSONP 响应 callback(<json>);，Content-Type 为 application/javascript
+// callback 不是合法的 JS 标识符路径时返回 400 HTTPError，防止脚本注入
+func (c *Context) JSONP(status int, callback string, data any) error {
+	if !jsonpCallbackRegex.MatchString(callback) {
+		return NewHTTPError(http.StatusBadRequest, "invalid jsonp callback")
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	c.SetHeader(HeaderContentType, MIMEApplicationJavaScriptCharsetUTF8)
+	c.SetStatus(status)
+	_, err = c.response.WriteString(callback + "(" + string(b) + ");")
+	return err
+}
+
+// JSONPFromQuery 从查询参数读取回调名后调用 JSONP，param 默认为 "callback"
+func (c *Context) JSONPFromQuery(status int, data any, param ...string) error {
+	name := "callback"
+	if len(param) > 0 {
+		name = param[0]
+	}
+	return c.JSONP(status, c.Query(name), data)
+}
+
+// jsonStreamFlushEvery JSONStream 每写入多少个元素刷新一次缓冲区
+const jsonStreamFlushEvery = 100
+
+// JSONStream 以 JSON 数组的形式逐个输出 ch 中的元素，不需要把整个结果集放进内存
+// 适合直接输出数据库游标的查询结果；ch 关闭后写入 "]" 结束数组
+// 响应在写入 "[" 时就已经提交，中途编码失败或客户端断开时只能停止输出并返回错误，
+// 此时客户端收到的是不完整的 JSON；路由返回的错误交给 c.Error，因为响应已经提交，
+// ErrHandler 不会再改写响应，只通过 c.Logger() 记录一条 "zest: error after response committed" 警告
+// JSONStream 提前返回后不再读取 ch，生产者应该同时监听 c.Context().Done() 以免阻塞
+func (c *Context) JSONStream(status int, ch <-chan any) error {
+	c.SetHeader(HeaderContentType, MIMEApplicationJSON)
+	c.SetStatus(status)
+	if _, err := c.response.WriteString("["); err != nil {
+		return err
+	}
+
+	// 元素先编码到 buf，成功后再连同逗号一起写入，编码失败时不会写出多余的逗号
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	done := c.Context().Done()
+	n := 0
+	for {
+		var v any
+		var ok bool
+		select {
+		case v, ok = <-ch:
+		case <-done:
+			return c.Context().Err()
+		}
+		if !ok {
+			break
+		}
+
+		buf.Reset()
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("json stream: element %d: %w", n, err)
+		}
+		if _, err := c.response.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		n++
+		if n%jsonStreamFlushEvery == 0 {
+			c.response.Flush()
+		}
+	}
+
+	_, err := c.response.WriteString("]")
+	return err
+}
@@ -0,0 +1,65 @@
+package zest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// discardResponseWriter 丢弃写入的内容，基准测试中不统计 ResponseRecorder 自身的分配
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+// streamJSONSerializer 直接编码到响应的 JSONSerializer，作为池化缓冲区的对照
+type streamJSONSerializer struct {
+	DefaultJSONSerializer
+}
+
+func (streamJSONSerializer) Serialize(c *Context, data any, status int) error {
+	c.SetStatus(status)
+	return json.NewEncoder(c.Response()).Encode(data)
+}
+
+func BenchmarkJSON(b *testing.B) {
+	type user struct {
+		ID    int      `json:"id"`
+		Name  string   `json:"name"`
+		Email string   `json:"email"`
+		Tags  []string `json:"tags"`
+	}
+	users := make([]user, 50)
+	for i := range users {
+		users[i] = user{ID: i, Name: "user", Email: "user@example.com", Tags: []string{"a", "b"}}
+	}
+
+	benchmarks := []struct {
+		name       string
+		serializer JSONSerializer
+	}{
+		{"pooled", DefaultJSONSerializer{}},
+		{"stream", streamJSONSerializer{}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			z := New()
+			z.JSONSerializer = bm.serializer
+			w := &discardResponseWriter{header: make(http.Header)}
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				c := z.NewContext(w, r)
+				if err := c.JSON(http.StatusOK, users); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
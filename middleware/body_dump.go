@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/lemonc7/zest"
+)
+
+// BodyDumpConfig BodyDump 中间件配置
+type BodyDumpConfig struct {
+	// Skip 返回 true 时跳过，请求和响应不做任何包装
+	Skip func(c *zest.Context) bool
+	// MaxBodySize 请求体和响应体各自最多记录的字节数，超出的部分照常传输，只是不再记录
+	// 默认 64KB
+	MaxBodySize int
+}
+
+// DefaultBodyDumpConfig 默认配置
+var DefaultBodyDumpConfig = BodyDumpConfig{
+	MaxBodySize: 64 << 10,
+}
+
+// BodyDump 返回记录请求体和响应体的中间件，请求结束后调用 handler，用于调试 Webhook 等集成
+// 请求体在被处理器读取的同时复制一份，响应体在写出的同时复制一份，都不会改变原有的读写行为，流式响应照常刷新
+// handler 拿到的只是处理器实际读取的部分；返回的错误会先交给错误处理器，错误响应同样会被记录
+func BodyDump(handler func(c *zest.Context, reqBody, resBody []byte), config ...BodyDumpConfig) zest.MiddlewareFunc {
+	cfg := DefaultBodyDumpConfig
+	if len(config) > 0 {
+		userCfg := config[0]
+		if userCfg.Skip != nil {
+			cfg.Skip = userCfg.Skip
+		}
+		if userCfg.MaxBodySize > 0 {
+			cfg.MaxBodySize = userCfg.MaxBodySize
+		}
+	}
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+
+			reqBuf := &limitedBuffer{limit: cfg.MaxBodySize}
+			if c.Request.Body != nil && c.Request.Body != http.NoBody {
+				c.Request.Body = teeReadCloser{
+					Reader: io.TeeReader(c.Request.Body, reqBuf),
+					Closer: c.Request.Body,
+				}
+			}
+
+			res := c.Response()
+			dw := &bodyDumpWriter{
+				ResponseWriter: res.ResponseWriter,
+				buf:            limitedBuffer{limit: cfg.MaxBodySize},
+			}
+			res.ResponseWriter = dw
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			res.ResponseWriter = dw.ResponseWriter
+
+			handler(c, reqBuf.Bytes(), dw.buf.Bytes())
+			return err
+		}
+	}
+}
+
+// teeReadCloser 读取时复制请求体，关闭时关闭原始请求体
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// limitedBuffer 最多保留 limit 字节的缓冲区，超出的部分被丢弃，Write 总是报告写入成功
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+// bodyDumpWriter 写出响应的同时复制一份
+type bodyDumpWriter struct {
+	http.ResponseWriter
+	buf limitedBuffer
+}
+
+func (w *bodyDumpWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.buf.Write(b[:n])
+	return n, err
+}
+
+func (w *bodyDumpWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *bodyDumpWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
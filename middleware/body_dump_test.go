@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lemonc7/zest"
+)
+
+func TestBodyDump(t *testing.T) {
+	echo := func(c *zest.Context) error {
+		b, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, string(b))
+	}
+
+	tests := []struct {
+		name        string
+		maxBodySize int
+		body        string
+		handler     zest.HandlerFunc
+		wantStatus  int
+		wantBody    string
+		wantReqDump string
+		// wantResDump 为空时与客户端收到的响应体相同
+		wantResDump string
+	}{
+		{
+			name:        "full body",
+			body:        "hello world",
+			handler:     echo,
+			wantStatus:  http.StatusOK,
+			wantBody:    "hello world",
+			wantReqDump: "hello world",
+		},
+		{
+			name:        "size cap truncates only the dump",
+			maxBodySize: 5,
+			body:        "hello world",
+			handler:     echo,
+			wantStatus:  http.StatusOK,
+			wantBody:    "hello world",
+			wantReqDump: "hello",
+			wantResDump: "hello",
+		},
+		{
+			name: "error response",
+			body: "hello world",
+			handler: func(c *zest.Context) error {
+				return zest.NewHTTPError(http.StatusTeapot, "short and stout")
+			},
+			wantStatus: http.StatusTeapot,
+			wantBody:   "short and stout",
+		},
+		{
+			name: "unread body",
+			body: "hello world",
+			handler: func(c *zest.Context) error {
+				return c.NoContent(http.StatusAccepted)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reqDump, resDump []byte
+			called := 0
+			z := zest.New()
+			z.Use(BodyDump(func(c *zest.Context, reqBody, resBody []byte) {
+				called++
+				reqDump, resDump = reqBody, resBody
+			}, BodyDumpConfig{MaxBodySize: tt.maxBodySize}))
+			z.POST("/", tt.handler)
+
+			rec := z.Test(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %q", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+			if called != 1 {
+				t.Fatalf("handler called %d times, want 1", called)
+			}
+			if string(reqDump) != tt.wantReqDump {
+				t.Errorf("request dump = %q, want %q", reqDump, tt.wantReqDump)
+			}
+			wantResDump := tt.wantResDump
+			if wantResDump == "" {
+				wantResDump = rec.Body.String()
+			}
+			if string(resDump) != wantResDump {
+				t.Errorf("response dump = %q, want %q", resDump, wantResDump)
+			}
+		})
+	}
+}
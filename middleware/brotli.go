@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"sync"
+
+	"github.com/lemonc7/zest"
+)
+
+// BrotliConfig Brotli 中间件配置
+type BrotliConfig struct {
+	// Skip 返回 true 时跳过压缩
+	Skip func(c *zest.Context) bool
+	// NewEncoder 创建 brotli 编码器，必填
+	// 为了不引入第三方依赖，编码器由使用方提供，例如 github.com/andybalholm/brotli：
+	//	func() middleware.Compressor { return brotli.NewWriterLevel(nil, brotli.DefaultCompression) }
+	NewEncoder func() Compressor
+	// MinLength 响应体小于该字节数时不压缩
+	// 默认与 Gzip 相同，1024
+	MinLength int
+	// ExcludedContentTypes 不压缩的 Content-Type 前缀
+	// 默认与 Gzip 相同
+	ExcludedContentTypes []string
+}
+
+// DefaultBrotliConfig 默认配置
+var DefaultBrotliConfig = BrotliConfig{
+	MinLength:            1024,
+	ExcludedContentTypes: defaultExcludedContentTypes,
+}
+
+// Brotli 返回 brotli 响应压缩中间件，只在客户端的 Accept-Encoding 包含 br 时生效
+// 与 Gzip 共用缓冲、MinLength 和 Content-Type 过滤逻辑，设置 Content-Encoding: br 和 Vary 响应头
+// 需要回退到 gzip 时，把 Brotli 注册在 Gzip 外层：z.Use(middleware.Brotli(cfg), middleware.Gzip())
+// 客户端支持 br 时由 Brotli 压缩，Gzip 不会重复压缩；不支持时交给 Gzip，两者都不支持时原样返回
+func Brotli(config BrotliConfig) zest.MiddlewareFunc {
+	if config.NewEncoder == nil {
+		panic("zest: Brotli requires NewEncoder")
+	}
+	if config.MinLength <= 0 {
+		config.MinLength = DefaultBrotliConfig.MinLength
+	}
+	if len(config.ExcludedContentTypes) == 0 {
+		config.ExcludedContentTypes = DefaultBrotliConfig.ExcludedContentTypes
+	}
+
+	pool := sync.Pool{
+		New: func() any { return config.NewEncoder() },
+	}
+
+	return compress(compressOptions{
+		encoding:             "br",
+		skip:                 config.Skip,
+		minLength:            config.MinLength,
+		excludedContentTypes: config.ExcludedContentTypes,
+		acquire:              func() Compressor { return pool.Get().(Compressor) },
+		release:              func(w Compressor) { pool.Put(w) },
+	})
+}
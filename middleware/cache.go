@@ -0,0 +1,333 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lemonc7/zest"
+)
+
+// CacheConfig 响应缓存中间件配置
+type CacheConfig struct {
+	// Skip 返回 true 时跳过，既不读取也不写入缓存
+	Skip func(c *zest.Context) bool
+	// TTL 每条缓存的有效期
+	// 可选，默认 1 分钟
+	TTL time.Duration
+	// VaryHeaders 参与缓存 key 的请求头，同一路径不同请求头值的响应分开缓存
+	// 可选，默认 ["Accept", "Accept-Encoding"]；Cache 在 Gzip 外层时必须包含 Accept-Encoding
+	VaryHeaders []string
+	// MaxBodySize 超过该大小的响应不缓存，照常透传
+	// 可选，默认 1MB
+	MaxBodySize int
+	// MaxEntries 默认内存存储最多保留的条目数，超出时淘汰最久未使用的条目
+	// 可选，默认 1000；设置了 Store 时忽略
+	MaxEntries int
+	// Store 缓存存储，可替换为 Redis 等实现
+	// 可选，默认使用 NewCacheMemoryStore(MaxEntries)
+	Store CacheStore
+}
+
+// DefaultCacheConfig 默认配置
+var DefaultCacheConfig = CacheConfig{
+	TTL:         time.Minute,
+	VaryHeaders: []string{zest.HeaderAccept, zest.HeaderAcceptEncoding},
+	MaxBodySize: 1 << 20,
+	MaxEntries:  1000,
+}
+
+// CacheStore 缓存存储接口，实现需要并发安全
+type CacheStore interface {
+	// Get 返回 key 对应的缓存，不存在或已过期时返回 nil
+	Get(key string) (*CacheEntry, error)
+	// Set 保存缓存，ttl 后过期
+	Set(key string, entry *CacheEntry, ttl time.Duration) error
+}
+
+// CacheEntry 一条缓存的响应
+type CacheEntry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Vary 响应的 Vary 头列出的请求头及其在原始请求中的值，命中时逐一比较，不一致视为未命中
+	Vary map[string]string
+}
+
+// Cache 返回缓存 GET 响应的中间件
+// 只缓存 200 响应，命中时直接写出保存的状态码、响应头和响应体，不再执行处理器，并设置 X-Cache: HIT；未命中时设置 X-Cache: MISS
+// HEAD 请求复用 GET 的缓存，但不写入缓存；响应设置了 Cache-Control: no-store 或 private、Set-Cookie、Vary: * 时不缓存
+// 缓存 key 包含 Host、路径和查询参数以及 VaryHeaders 的值，响应自己的 Vary 头列出的请求头同样参与匹配
+// 按 RFC 9111 3.5 节，带 Authorization 或 Cookie 的请求只有在响应明确设置 Cache-Control: public 或 s-maxage 时才缓存，避免不同用户之间共享响应
+// 存储出错时不影响请求，只记录到 c.Logger()
+func Cache(config CacheConfig) zest.MiddlewareFunc {
+	cfg := DefaultCacheConfig
+	cfg.Skip = config.Skip
+	if config.TTL > 0 {
+		cfg.TTL = config.TTL
+	}
+	if config.VaryHeaders != nil {
+		cfg.VaryHeaders = config.VaryHeaders
+	}
+	if config.MaxBodySize > 0 {
+		cfg.MaxBodySize = config.MaxBodySize
+	}
+	if config.MaxEntries > 0 {
+		cfg.MaxEntries = config.MaxEntries
+	}
+	cfg.Store = config.Store
+	if cfg.Store == nil {
+		cfg.Store = NewCacheMemoryStore(cfg.MaxEntries)
+	}
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+			method := c.Request.Method
+			if method != http.MethodGet && method != http.MethodHead {
+				return next(c)
+			}
+
+			key := cacheKey(c.Request, cfg.VaryHeaders)
+			entry, err := cfg.Store.Get(key)
+			if err != nil {
+				c.Logger().Warn("cache: get failed", "key", key, "error", err)
+			}
+			if entry != nil && varyMatch(c.Request, entry.Vary) {
+				header := c.Response().Header()
+				for k, v := range entry.Header {
+					header[k] = v
+				}
+				header.Set(zest.HeaderXCache, "HIT")
+				c.Response().WriteHeader(entry.Status)
+				if method == http.MethodHead {
+					return nil
+				}
+				_, err := c.Response().Write(entry.Body)
+				return err
+			}
+
+			c.SetHeader(zest.HeaderXCache, "MISS")
+			if method == http.MethodHead {
+				return next(c)
+			}
+
+			res := c.Response()
+			cw := &cacheWriter{
+				ResponseWriter: res.ResponseWriter,
+				maxBodySize:    cfg.MaxBodySize,
+			}
+			res.ResponseWriter = cw
+
+			err = next(c)
+			res.ResponseWriter = cw.ResponseWriter
+			if err != nil || cw.status != http.StatusOK || cw.skip || !cacheable(c.Request, res.Header()) {
+				return err
+			}
+
+			header := res.Header().Clone()
+			header.Del(zest.HeaderXCache)
+			entry = &CacheEntry{
+				Status: cw.status,
+				Header: header,
+				Body:   cw.buf.Bytes(),
+				Vary:   varyValues(c.Request, header),
+			}
+			if err := cfg.Store.Set(key, entry, cfg.TTL); err != nil {
+				c.Logger().Warn("cache: set failed", "key", key, "error", err)
+			}
+			return nil
+		}
+	}
+}
+
+// cacheKey 由 Host、路径、查询参数和 varyHeaders 的值组成，HEAD 和 GET 共用同一个 key
+func cacheKey(r *http.Request, varyHeaders []string) string {
+	var b strings.Builder
+	b.WriteString(r.Host)
+	b.WriteString(r.URL.RequestURI())
+	for _, h := range varyHeaders {
+		b.WriteByte('\n')
+		b.WriteString(h)
+		b.WriteByte(':')
+		b.WriteString(r.Header.Get(h))
+	}
+	return b.String()
+}
+
+// cacheable 根据请求和处理器设置的响应头判断能否缓存
+func cacheable(r *http.Request, header http.Header) bool {
+	if header.Get(zest.HeaderSetCookie) != "" || slices.Contains(varyNames(header), "*") {
+		return false
+	}
+	shared := false
+	for directive := range strings.SplitSeq(header.Get(zest.HeaderCacheControl), ",") {
+		name, _, _ := strings.Cut(directive, "=")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "no-store", "private":
+			return false
+		case "public", "s-maxage":
+			shared = true
+		}
+	}
+	// 带凭证的请求得到的响应通常因用户而异，除非处理器明确声明可以共享
+	if r.Header.Get(zest.HeaderAuthorization) != "" || r.Header.Get(zest.HeaderCookie) != "" {
+		return shared
+	}
+	return true
+}
+
+// varyNames 返回响应 Vary 头列出的请求头名称
+func varyNames(header http.Header) []string {
+	var names []string
+	for _, v := range header.Values(zest.HeaderVary) {
+		for name := range strings.SplitSeq(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// varyValues 记录响应 Vary 头列出的请求头在请求 r 中的值
+func varyValues(r *http.Request, header http.Header) map[string]string {
+	names := varyNames(header)
+	if len(names) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		values[name] = r.Header.Get(name)
+	}
+	return values
+}
+
+// varyMatch 判断请求 r 与缓存条目记录的 Vary 请求头是否一致
+func varyMatch(r *http.Request, vary map[string]string) bool {
+	for name, v := range vary {
+		if r.Header.Get(name) != v {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheWriter 写出响应的同时复制一份，超过 maxBodySize 或调用 Flush 时放弃缓存
+type cacheWriter struct {
+	http.ResponseWriter
+	maxBodySize int
+
+	status int
+	buf    bytes.Buffer
+	skip   bool
+}
+
+func (w *cacheWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	if !w.skip {
+		if w.buf.Len()+n > w.maxBodySize {
+			w.skip = true
+			w.buf = bytes.Buffer{}
+		} else {
+			w.buf.Write(b[:n])
+		}
+	}
+	return n, err
+}
+
+// Flush 流式响应不缓存
+func (w *cacheWriter) Flush() {
+	w.skip = true
+	w.buf = bytes.Buffer{}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *cacheWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// CacheMemoryStore 基于内存的 LRU 缓存存储，并发安全
+type CacheMemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type cacheItem struct {
+	key       string
+	entry     *CacheEntry
+	expiresAt time.Time
+}
+
+// NewCacheMemoryStore 创建最多保留 maxEntries 条缓存的内存存储，maxEntries 不大于 0 时为 1000
+// 过期的条目在下一次读取时删除，超出容量时淘汰最久未使用的条目
+func NewCacheMemoryStore(maxEntries int) *CacheMemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheConfig.MaxEntries
+	}
+	return &CacheMemoryStore{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get 实现 CacheStore
+func (s *CacheMemoryStore) Get(key string) (*CacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+	item := el.Value.(*cacheItem)
+	if time.Now().After(item.expiresAt) {
+		s.ll.Remove(el)
+		delete(s.items, key)
+		return nil, nil
+	}
+	s.ll.MoveToFront(el)
+	return item.entry, nil
+}
+
+// Set 实现 CacheStore
+func (s *CacheMemoryStore) Set(key string, entry *CacheEntry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if el, ok := s.items[key]; ok {
+		el.Value = &cacheItem{key: key, entry: entry, expiresAt: expiresAt}
+		s.ll.MoveToFront(el)
+		return nil
+	}
+
+	s.items[key] = s.ll.PushFront(&cacheItem{key: key, entry: entry, expiresAt: expiresAt})
+	for s.ll.Len() > s.maxEntries {
+		oldest := s.ll.Back()
+		s.ll.Remove(oldest)
+		delete(s.items, oldest.Value.(*cacheItem).key)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lemonc7/zest"
+)
+
+func TestCache(t *testing.T) {
+	type request struct {
+		host   string
+		header map[string]string
+		want   string
+		cache  string
+	}
+	tests := []struct {
+		name         string
+		cacheControl string
+		vary         string
+		requests     []request
+	}{
+		{
+			name: "anonymous responses are shared",
+			requests: []request{
+				{want: "user=", cache: "MISS"},
+				{want: "user=", cache: "HIT"},
+			},
+		},
+		{
+			name: "authorized responses are not shared",
+			requests: []request{
+				{header: map[string]string{"Authorization": "alice"}, want: "user=alice", cache: "MISS"},
+				{header: map[string]string{"Authorization": "bob"}, want: "user=bob", cache: "MISS"},
+			},
+		},
+		{
+			name: "cookie responses are not shared",
+			requests: []request{
+				{header: map[string]string{"Cookie": "alice"}, want: "user=alice", cache: "MISS"},
+				{header: map[string]string{"Cookie": "bob"}, want: "user=bob", cache: "MISS"},
+			},
+		},
+		{
+			name:         "public authorized responses are shared",
+			cacheControl: "public, max-age=60",
+			requests: []request{
+				{header: map[string]string{"Authorization": "alice"}, want: "user=alice", cache: "MISS"},
+				{header: map[string]string{"Authorization": "bob"}, want: "user=alice", cache: "HIT"},
+			},
+		},
+		{
+			name:         "s-maxage authorized responses are shared",
+			cacheControl: "s-maxage=60",
+			requests: []request{
+				{header: map[string]string{"Authorization": "alice"}, want: "user=alice", cache: "MISS"},
+				{header: map[string]string{"Authorization": "bob"}, want: "user=alice", cache: "HIT"},
+			},
+		},
+		{
+			name:         "no-store is not cached",
+			cacheControl: "no-store",
+			requests: []request{
+				{want: "user=", cache: "MISS"},
+				{want: "user=", cache: "MISS"},
+			},
+		},
+		{
+			name: "host is part of the key",
+			requests: []request{
+				{host: "a.example.com", want: "user=", cache: "MISS"},
+				{host: "b.example.com", want: "user=", cache: "MISS"},
+				{host: "a.example.com", want: "user=", cache: "HIT"},
+			},
+		},
+		{
+			name: "response vary header is matched",
+			vary: "X-Lang",
+			requests: []request{
+				{header: map[string]string{"X-Lang": "en"}, want: "user=", cache: "MISS"},
+				{header: map[string]string{"X-Lang": "zh"}, want: "user=", cache: "MISS"},
+				{header: map[string]string{"X-Lang": "zh"}, want: "user=", cache: "HIT"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := zest.New()
+			z.Use(Cache(CacheConfig{}))
+			z.GET("/me", func(c *zest.Context) error {
+				if tt.cacheControl != "" {
+					c.SetHeader(zest.HeaderCacheControl, tt.cacheControl)
+				}
+				if tt.vary != "" {
+					c.SetHeader(zest.HeaderVary, tt.vary)
+				}
+				user := c.Request.Header.Get(zest.HeaderAuthorization)
+				if user == "" {
+					user = c.Request.Header.Get(zest.HeaderCookie)
+				}
+				return c.String(http.StatusOK, "user="+user)
+			})
+
+			for i, r := range tt.requests {
+				req := httptest.NewRequest(http.MethodGet, "/me", nil)
+				if r.host != "" {
+					req.Host = r.host
+				}
+				for k, v := range r.header {
+					req.Header.Set(k, v)
+				}
+				rec := z.Test(req)
+				if rec.Body.String() != r.want {
+					t.Errorf("request %d: body = %q, want %q", i, rec.Body.String(), r.want)
+				}
+				if got := rec.Header().Get(zest.HeaderXCache); got != r.cache {
+					t.Errorf("request %d: X-Cache = %q, want %q", i, got, r.cache)
+				}
+			}
+		})
+	}
+}
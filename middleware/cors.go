@@ -12,13 +12,16 @@ import (
 // CORSConfig CORS 配置
 type CORSConfig struct {
 	// 允许的域名，["*"] 表示所有
+	// 支持子域名通配符，如 "https://*.example.com"
 	AllowOrigins []string
 	// AllowOriginFunc 自定义判断 origin 是否合法的函数
-	// 如果设置了此函数，AllowOrigins 将被忽略
-	AllowOriginFunc func(origin string) bool
+	// 如果设置了此函数，AllowOrigins 将被忽略；返回的 error 会交给错误处理器
+	AllowOriginFunc func(origin string) (bool, error)
 	// 允许的 HTTP 方法
 	AllowMethods []string
 	// 允许的请求头
+	// 为空时回显预检请求的 Access-Control-Request-Headers，即允许浏览器请求的所有请求头，
+	// 并添加 Vary: Access-Control-Request-Headers；默认为空
 	AllowHeaders []string
 	// 暴露给客户端的响应头
 	ExposeHeaders []string
@@ -26,6 +29,10 @@ type CORSConfig struct {
 	AllowCredentials bool
 	// 预检请求缓存时间（秒）
 	MaxAge time.Duration
+	// AllowPrivateNetwork 允许公网页面访问内网或本机的服务（Chrome 的 Private Network Access）
+	// 开启后，带有 Access-Control-Request-Private-Network: true 的预检请求会收到
+	// Access-Control-Allow-Private-Network: true，其他请求不受影响
+	AllowPrivateNetwork bool
 }
 
 // DefaultCORSConfig 默认配置
@@ -39,11 +46,6 @@ var DefaultCORSConfig = CORSConfig{
 		http.MethodDelete,
 		http.MethodOptions,
 	},
-	AllowHeaders: []string{
-		"Origin",
-		"Content-Type",
-		"Authorization",
-	},
 	MaxAge: 24 * time.Hour,
 }
 
@@ -81,6 +83,7 @@ func CORS(config ...CORSConfig) zest.MiddlewareFunc {
 		if userCfg.MaxAge > 0 {
 			cfg.MaxAge = userCfg.MaxAge
 		}
+		cfg.AllowPrivateNetwork = userCfg.AllowPrivateNetwork
 	}
 
 	methods := strings.Join(cfg.AllowMethods, ", ")
@@ -88,9 +91,29 @@ func CORS(config ...CORSConfig) zest.MiddlewareFunc {
 	expose := strings.Join(cfg.ExposeHeaders, ", ")
 	maxAge := strconv.FormatInt(int64(cfg.MaxAge.Seconds()), 10)
 
+	// 预先拆分通配符域名，避免每个请求重复解析
+	allowAll := false
+	exactOrigins := make(map[string]struct{})
+	var wildcardOrigins []originPattern
+	for _, o := range cfg.AllowOrigins {
+		switch {
+		case o == "*":
+			allowAll = true
+		case strings.Contains(o, "*"):
+			prefix, suffix, _ := strings.Cut(strings.ToLower(o), "*")
+			wildcardOrigins = append(wildcardOrigins, originPattern{prefix: prefix, suffix: suffix})
+		default:
+			exactOrigins[strings.ToLower(o)] = struct{}{}
+		}
+	}
+
 	return func(next zest.HandlerFunc) zest.HandlerFunc {
 		return func(c *zest.Context) error {
-			origin := c.Request.Header.Get("Origin")
+			// 响应头随 Origin 变化，需要告诉缓存服务器
+			// 没有 Origin 的请求同样设置，否则共享缓存可能把不带 CORS 头的响应返回给跨域请求
+			c.Response().Header().Add(zest.HeaderVary, zest.HeaderOrigin)
+
+			origin := c.Request.Header.Get(zest.HeaderOrigin)
 			// 即使没有 Origin，也可以是同源请求，CORS 规范通常只在跨域时生效
 			// 但很多客户端库会发 Origin，保守起见如果没 Origin 直接放行
 			if origin == "" {
@@ -101,21 +124,32 @@ func CORS(config ...CORSConfig) zest.MiddlewareFunc {
 			allowOrigin := ""
 
 			if cfg.AllowOriginFunc != nil {
-				if cfg.AllowOriginFunc(origin) {
+				allowed, err := cfg.AllowOriginFunc(origin)
+				if err != nil {
+					return err
+				}
+				if allowed {
 					allowOrigin = origin
 				}
 			} else {
-				for _, o := range cfg.AllowOrigins {
-					if o == "*" || o == origin {
-						if cfg.AllowCredentials && o == "*" {
+				lower := strings.ToLower(origin)
+				if _, ok := exactOrigins[lower]; ok {
+					allowOrigin = origin
+				} else if allowAll {
+					allowOrigin = "*"
+				} else {
+					for _, p := range wildcardOrigins {
+						if p.match(lower) {
 							allowOrigin = origin
-						} else {
-							allowOrigin = o
+							break
 						}
-						break
 					}
 				}
 			}
+			// 携带凭证时规范不允许返回 "*"，只能回显具体的 Origin
+			if allowOrigin == "*" && cfg.AllowCredentials {
+				allowOrigin = origin
+			}
 
 			if allowOrigin == "" {
 				// Origin 不被允许，通常做法是：
@@ -126,25 +160,35 @@ func CORS(config ...CORSConfig) zest.MiddlewareFunc {
 			}
 
 			// 设置 CORS 响应头
-			c.SetHeader("Access-Control-Allow-Origin", allowOrigin)
-			// Vary Header 非常重要，告诉缓存服务器响应内容取决于 Origin
-			if len(cfg.AllowOrigins) > 1 || cfg.AllowOriginFunc != nil {
-				c.SetHeader("Vary", "Origin")
-			}
+			c.SetHeader(zest.HeaderAccessControlAllowOrigin, allowOrigin)
 
 			if cfg.AllowCredentials {
-				c.SetHeader("Access-Control-Allow-Credentials", "true")
+				c.SetHeader(zest.HeaderAccessControlAllowCredentials, "true")
 			}
 			if expose != "" {
-				c.SetHeader("Access-Control-Expose-Headers", expose)
+				c.SetHeader(zest.HeaderAccessControlExposeHeaders, expose)
 			}
 
-			// 处理预检请求 (OPTIONS)
-			if c.Request.Method == http.MethodOptions {
-				c.SetHeader("Access-Control-Allow-Methods", methods)
-				c.SetHeader("Access-Control-Allow-Headers", headers)
+			// 处理预检请求：OPTIONS 且带有 Access-Control-Request-Method
+			// 普通的 OPTIONS 请求交给路由处理
+			if c.Request.Method == http.MethodOptions &&
+				c.Request.Header.Get(zest.HeaderAccessControlRequestMethod) != "" {
+				c.SetHeader(zest.HeaderAccessControlAllowMethods, methods)
+				if headers != "" {
+					c.SetHeader(zest.HeaderAccessControlAllowHeaders, headers)
+				} else {
+					// 没有配置 AllowHeaders 时允许浏览器请求的所有请求头，响应随该请求头变化
+					c.Response().Header().Add(zest.HeaderVary, zest.HeaderAccessControlRequestHeaders)
+					if requested := c.Request.Header.Get(zest.HeaderAccessControlRequestHeaders); requested != "" {
+						c.SetHeader(zest.HeaderAccessControlAllowHeaders, requested)
+					}
+				}
 				if cfg.MaxAge > 0 {
-					c.SetHeader("Access-Control-Max-Age", maxAge)
+					c.SetHeader(zest.HeaderAccessControlMaxAge, maxAge)
+				}
+				if cfg.AllowPrivateNetwork &&
+					c.Request.Header.Get(zest.HeaderAccessControlRequestPrivateNetwork) == "true" {
+					c.SetHeader(zest.HeaderAccessControlAllowPrivateNetwork, "true")
 				}
 				return c.NoContent(http.StatusNoContent)
 			}
@@ -153,3 +197,18 @@ func CORS(config ...CORSConfig) zest.MiddlewareFunc {
 		}
 	}
 }
+
+// originPattern 预先拆分好的通配符域名，"https://*.example.com" 拆为前缀 "https://" 和后缀 ".example.com"
+type originPattern struct {
+	prefix string
+	suffix string
+}
+
+// match 判断 origin 是否匹配，通配符部分不能为空，也不能包含 /
+func (p originPattern) match(origin string) bool {
+	if len(origin) <= len(p.prefix)+len(p.suffix) ||
+		!strings.HasPrefix(origin, p.prefix) || !strings.HasSuffix(origin, p.suffix) {
+		return false
+	}
+	return !strings.Contains(origin[len(p.prefix):len(origin)-len(p.suffix)], "/")
+}
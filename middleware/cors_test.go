@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/lemonc7/zest"
+)
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         CORSConfig
+		method         string
+		origin         string
+		requestMethod  string
+		requestHeaders string
+		wantStatus     int
+		wantOrigin     string
+		wantCreds      string
+		wantMethods    string
+		wantHeaders    string
+		wantVary       []string
+	}{
+		{
+			name:       "wildcard",
+			config:     CORSConfig{},
+			method:     http.MethodGet,
+			origin:     "https://a.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "*",
+			wantVary:   []string{zest.HeaderOrigin},
+		},
+		{
+			name:       "no origin still varies",
+			config:     CORSConfig{},
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantVary:   []string{zest.HeaderOrigin},
+		},
+		{
+			name:       "exact match among several origins",
+			config:     CORSConfig{AllowOrigins: []string{"https://a.com", "https://b.com"}},
+			method:     http.MethodGet,
+			origin:     "https://b.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "https://b.com",
+			wantVary:   []string{zest.HeaderOrigin},
+		},
+		{
+			name:       "origin not allowed still varies",
+			config:     CORSConfig{AllowOrigins: []string{"https://a.com", "https://b.com"}},
+			method:     http.MethodGet,
+			origin:     "https://evil.com",
+			wantStatus: http.StatusOK,
+			wantVary:   []string{zest.HeaderOrigin},
+		},
+		{
+			name:       "subdomain wildcard",
+			config:     CORSConfig{AllowOrigins: []string{"https://*.example.com"}},
+			method:     http.MethodGet,
+			origin:     "https://api.example.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "https://api.example.com",
+			wantVary:   []string{zest.HeaderOrigin},
+		},
+		{
+			name:       "credentialed wildcard echoes origin",
+			config:     CORSConfig{AllowCredentials: true},
+			method:     http.MethodGet,
+			origin:     "https://a.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "https://a.com",
+			wantCreds:  "true",
+			wantVary:   []string{zest.HeaderOrigin},
+		},
+		{
+			name: "preflight with configured methods and headers",
+			config: CORSConfig{
+				AllowOrigins: []string{"https://a.com"},
+				AllowMethods: []string{http.MethodGet, http.MethodPut},
+				AllowHeaders: []string{"X-Token", zest.HeaderContentType},
+			},
+			method:        http.MethodOptions,
+			origin:        "https://a.com",
+			requestMethod: http.MethodPut,
+			wantStatus:    http.StatusNoContent,
+			wantOrigin:    "https://a.com",
+			wantMethods:   "GET, PUT",
+			wantHeaders:   "X-Token, Content-Type",
+			wantVary:      []string{zest.HeaderOrigin},
+		},
+		{
+			name:           "preflight echoes requested headers",
+			config:         CORSConfig{},
+			method:         http.MethodOptions,
+			origin:         "https://a.com",
+			requestMethod:  http.MethodPost,
+			requestHeaders: "X-Custom",
+			wantStatus:     http.StatusNoContent,
+			wantOrigin:     "*",
+			wantMethods:    "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+			wantHeaders:    "X-Custom",
+			wantVary:       []string{zest.HeaderOrigin, zest.HeaderAccessControlRequestHeaders},
+		},
+		{
+			name:       "plain OPTIONS reaches the route",
+			config:     CORSConfig{},
+			method:     http.MethodOptions,
+			origin:     "https://a.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "*",
+			wantVary:   []string{zest.HeaderOrigin},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := zest.New()
+			z.Use(CORS(tt.config))
+			ok := func(c *zest.Context) error { return c.String(http.StatusOK, "ok") }
+			z.GET("/", ok)
+			z.OPTIONS("/", ok)
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.origin != "" {
+				req.Header.Set(zest.HeaderOrigin, tt.origin)
+			}
+			if tt.requestMethod != "" {
+				req.Header.Set(zest.HeaderAccessControlRequestMethod, tt.requestMethod)
+			}
+			if tt.requestHeaders != "" {
+				req.Header.Set(zest.HeaderAccessControlRequestHeaders, tt.requestHeaders)
+			}
+			rec := z.Test(req)
+			h := rec.Header()
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			checks := []struct{ header, want string }{
+				{zest.HeaderAccessControlAllowOrigin, tt.wantOrigin},
+				{zest.HeaderAccessControlAllowCredentials, tt.wantCreds},
+				{zest.HeaderAccessControlAllowMethods, tt.wantMethods},
+				{zest.HeaderAccessControlAllowHeaders, tt.wantHeaders},
+			}
+			for _, ch := range checks {
+				if got := h.Get(ch.header); got != ch.want {
+					t.Errorf("%s = %q, want %q", ch.header, got, ch.want)
+				}
+			}
+			if got := h.Values(zest.HeaderVary); !slices.Equal(got, tt.wantVary) {
+				t.Errorf("Vary = %q, want %q", got, tt.wantVary)
+			}
+		})
+	}
+}
+
+func TestCORSPrivateNetwork(t *testing.T) {
+	tests := []struct {
+		name           string
+		allow          bool
+		method         string
+		requestMethod  string
+		requestPrivate string
+		want           string
+	}{
+		{"preflight asking", true, http.MethodOptions, http.MethodGet, "true", "true"},
+		{"preflight not asking", true, http.MethodOptions, http.MethodGet, "", ""},
+		{"preflight asking but disabled", false, http.MethodOptions, http.MethodGet, "true", ""},
+		{"simple request asking", true, http.MethodGet, "", "true", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := zest.New()
+			z.Use(CORS(CORSConfig{AllowPrivateNetwork: tt.allow}))
+			z.GET("/", func(c *zest.Context) error { return c.String(http.StatusOK, "ok") })
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			req.Header.Set(zest.HeaderOrigin, "https://app.example.com")
+			if tt.requestMethod != "" {
+				req.Header.Set(zest.HeaderAccessControlRequestMethod, tt.requestMethod)
+			}
+			if tt.requestPrivate != "" {
+				req.Header.Set(zest.HeaderAccessControlRequestPrivateNetwork, tt.requestPrivate)
+			}
+			rec := z.Test(req)
+
+			if got := rec.Header().Get(zest.HeaderAccessControlAllowPrivateNetwork); got != tt.want {
+				t.Errorf("%s = %q, want %q", zest.HeaderAccessControlAllowPrivateNetwork, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lemonc7/zest"
+)
+
+// DecompressConfig Decompress 中间件配置
+type DecompressConfig struct {
+	// Skip 返回 true 时跳过，请求体保持压缩状态
+	Skip func(c *zest.Context) bool
+}
+
+// DefaultDecompressConfig 默认配置
+var DefaultDecompressConfig = DecompressConfig{}
+
+// Decompress 返回解压请求体的中间件，请求头 Content-Encoding 为 gzip 时把 c.Request.Body 替换为解压后的数据
+// 替换后删除请求的 Content-Encoding 和 Content-Length，处理器和 c.Bind 看到的是原始内容；其他编码原样交给处理器
+// 请求体不是合法的 gzip 数据时返回 400
+// 与 Gzip 同时安装时互不影响：客户端可以发送 gzip 请求体，同时按 Accept-Encoding 收到 gzip 响应
+// 解压后的大小不受请求体原始大小限制，c.Bind 仍按 BindBodyLimit 限制，自行读取请求体时应使用 http.MaxBytesReader
+func Decompress(config ...DecompressConfig) zest.MiddlewareFunc {
+	cfg := DefaultDecompressConfig
+	if len(config) > 0 {
+		userCfg := config[0]
+		if userCfg.Skip != nil {
+			cfg.Skip = userCfg.Skip
+		}
+	}
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+			r := c.Request
+			if !strings.EqualFold(strings.TrimSpace(r.Header.Get(zest.HeaderContentEncoding)), "gzip") ||
+				r.Body == nil || r.Body == http.NoBody {
+				return next(c)
+			}
+
+			gr := gzipReaderPool.Get().(*gzip.Reader)
+			if err := gr.Reset(r.Body); err != nil {
+				gzipReaderPool.Put(gr)
+				return zest.NewHTTPError(http.StatusBadRequest, "invalid gzip body").Wrap(err)
+			}
+			body := &gzipReadCloser{zr: gr, body: r.Body}
+			defer body.release()
+
+			r.Body = body
+			r.Header.Del(zest.HeaderContentEncoding)
+			r.Header.Del(zest.HeaderContentLength)
+			r.ContentLength = -1
+
+			return next(c)
+		}
+	}
+}
+
+var gzipReaderPool = sync.Pool{
+	New: func() any {
+		return new(gzip.Reader)
+	},
+}
+
+// gzipReadCloser 读取解压后的数据，关闭时关闭原始请求体
+type gzipReadCloser struct {
+	zr   *gzip.Reader
+	body io.ReadCloser
+}
+
+func (g *gzipReadCloser) Read(p []byte) (int, error) {
+	if g.zr == nil {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return g.zr.Read(p)
+}
+
+func (g *gzipReadCloser) Close() error {
+	return g.body.Close()
+}
+
+// release 在处理链结束后归还 gzip.Reader
+// 处理器可能没有读完或关闭请求体，所以不在 Close 中归还；之后再读取会返回 io.ErrUnexpectedEOF
+func (g *gzipReadCloser) release() {
+	if g.zr == nil {
+		return
+	}
+	gzipReaderPool.Put(g.zr)
+	g.zr = nil
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lemonc7/zest"
+)
+
+type echoBody struct {
+	Msg string `json:"msg"`
+}
+
+func (echoBody) Validate() error { return nil }
+
+func gzipBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompressGzipRoundTrip(t *testing.T) {
+	z := zest.New()
+	z.Use(Gzip(), Decompress())
+	z.POST("/echo", func(c *zest.Context) error {
+		if enc := c.Request.Header.Get(zest.HeaderContentEncoding); enc != "" {
+			t.Errorf("handler sees Content-Encoding %q", enc)
+		}
+		var in echoBody
+		if err := c.Bind(&in); err != nil {
+			return err
+		}
+		// 超过 Gzip 默认的 MinLength，确保响应会被压缩
+		return c.String(http.StatusOK, strings.Repeat(in.Msg, 600))
+	})
+
+	want := strings.Repeat("hi", 600)
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{"gzip", "gzip", true},
+		{"gzip with quality", "br;q=1, gzip;q=0.8", true},
+		{"identity", "identity", false},
+		{"gzip refused", "gzip;q=0", false},
+		{"no accept-encoding", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(gzipBytes(t, `{"msg":"hi"}`)))
+			req.Header.Set(zest.HeaderContentType, zest.MIMEApplicationJSON)
+			req.Header.Set(zest.HeaderContentEncoding, "gzip")
+			if tt.acceptEncoding != "" {
+				req.Header.Set(zest.HeaderAcceptEncoding, tt.acceptEncoding)
+			}
+			rec := z.Test(req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %q", rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get(zest.HeaderVary); got != zest.HeaderAcceptEncoding {
+				t.Errorf("Vary = %q, want %q", got, zest.HeaderAcceptEncoding)
+			}
+
+			body := rec.Body.Bytes()
+			gotGzip := rec.Header().Get(zest.HeaderContentEncoding) == "gzip"
+			if gotGzip != tt.wantGzip {
+				t.Fatalf("Content-Encoding = %q, want gzip %v", rec.Header().Get(zest.HeaderContentEncoding), tt.wantGzip)
+			}
+			if gotGzip {
+				gr, err := gzip.NewReader(bytes.NewReader(body))
+				if err != nil {
+					t.Fatalf("response is not valid gzip: %v", err)
+				}
+				if body, err = io.ReadAll(gr); err != nil {
+					t.Fatalf("response is not valid gzip: %v", err)
+				}
+			}
+			if string(body) != want {
+				t.Errorf("body = %q, want %q", body, want)
+			}
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	z := zest.New()
+	z.Use(Decompress())
+	z.POST("/", func(c *zest.Context) error {
+		b, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, string(b))
+	})
+
+	tests := []struct {
+		name            string
+		contentEncoding string
+		body            []byte
+		wantStatus      int
+		wantBody        string
+	}{
+		{"gzip", "gzip", gzipBytes(t, "plain text"), http.StatusOK, "plain text"},
+		{"gzip uppercase", "GZIP", gzipBytes(t, "plain text"), http.StatusOK, "plain text"},
+		{"no encoding", "", []byte("plain text"), http.StatusOK, "plain text"},
+		{"other encoding passes through", "br", []byte("raw"), http.StatusOK, "raw"},
+		{"invalid gzip", "gzip", []byte("not gzip"), http.StatusBadRequest, "invalid gzip body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			if tt.contentEncoding != "" {
+				req.Header.Set(zest.HeaderContentEncoding, tt.contentEncoding)
+			}
+			rec := z.Test(req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want to contain %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
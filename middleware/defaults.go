@@ -0,0 +1,11 @@
+package middleware
+
+import "github.com/lemonc7/zest"
+
+// UseDefaults 按推荐顺序注册常用中间件：Recovery → RequestID → Logger
+// Recovery 在最外层，能捕获 RequestID、Logger 和 Handler 中的 panic；
+// RequestID 在 Logger 之前，日志中才能记录到请求 ID
+// 需要自定义配置时，按同样的顺序调用 z.Use 即可
+func UseDefaults(z *zest.Zest) {
+	z.Use(Recovery(), RequestID(), Logger())
+}
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strings"
+
+	"github.com/lemonc7/zest"
+)
+
+// ETagConfig ETag 中间件配置
+type ETagConfig struct {
+	// Skip 返回 true 时跳过
+	Skip func(c *zest.Context) bool
+	// Weak 生成弱 ETag（W/"..."）
+	// 默认 false，即强 ETag
+	Weak bool
+	// Hash 计算 ETag 使用的哈希函数
+	// 默认 sha1.New
+	Hash func() hash.Hash
+	// MaxBodySize 超过该大小的响应不计算 ETag，直接透传，避免缓冲大文件或流式响应
+	// 默认 1MB
+	MaxBodySize int
+}
+
+// DefaultETagConfig 默认配置
+var DefaultETagConfig = ETagConfig{
+	Hash:        sha1.New,
+	MaxBodySize: 1 << 20,
+}
+
+// ETag 返回自动生成 ETag 的中间件
+// 只处理 GET/HEAD 请求的 200 响应；If-None-Match 匹配时返回 304 且不发送响应体
+// 处理器已经设置了 ETag 时沿用该值，只做条件判断
+func ETag(config ...ETagConfig) zest.MiddlewareFunc {
+	cfg := DefaultETagConfig
+	if len(config) > 0 {
+		userCfg := config[0]
+		if userCfg.Skip != nil {
+			cfg.Skip = userCfg.Skip
+		}
+		cfg.Weak = userCfg.Weak
+		if userCfg.Hash != nil {
+			cfg.Hash = userCfg.Hash
+		}
+		if userCfg.MaxBodySize > 0 {
+			cfg.MaxBodySize = userCfg.MaxBodySize
+		}
+	}
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+			if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+				return next(c)
+			}
+
+			res := c.Response()
+			ew := &etagResponseWriter{
+				ResponseWriter: res.ResponseWriter,
+				maxBodySize:    cfg.MaxBodySize,
+			}
+			res.ResponseWriter = ew
+
+			err := next(c)
+
+			res.ResponseWriter = ew.ResponseWriter
+			// 超出大小限制已经透传，或者处理器什么都没写（交给错误处理器）
+			if ew.passthrough || ew.status == 0 {
+				if ew.passthrough {
+					res.Size = ew.written
+				}
+				return err
+			}
+
+			header := res.Header()
+			etag := header.Get("ETag")
+			if etag == "" && ew.status == http.StatusOK {
+				h := cfg.Hash()
+				h.Write(ew.buf.Bytes())
+				etag = `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+				if cfg.Weak {
+					etag = "W/" + etag
+				}
+				header.Set("ETag", etag)
+			}
+
+			if etag != "" && ew.status == http.StatusOK &&
+				etagMatch(c.Request.Header.Get("If-None-Match"), etag) {
+				// 304 不能携带实体相关的响应头
+				header.Del(zest.HeaderContentType)
+				header.Del(zest.HeaderContentLength)
+				ew.ResponseWriter.WriteHeader(http.StatusNotModified)
+				res.Status = http.StatusNotModified
+				res.Size = 0
+				return err
+			}
+
+			ew.ResponseWriter.WriteHeader(ew.status)
+			n, _ := ew.ResponseWriter.Write(ew.buf.Bytes())
+			res.Size = int64(n)
+			return err
+		}
+	}
+}
+
+// etagResponseWriter 缓冲响应体，超过 maxBodySize 或调用 Flush 时转为透传
+type etagResponseWriter struct {
+	http.ResponseWriter
+	maxBodySize int
+
+	status      int
+	buf         bytes.Buffer
+	passthrough bool
+	written     int64
+}
+
+func (ew *etagResponseWriter) WriteHeader(code int) {
+	if ew.status == 0 {
+		ew.status = code
+	}
+}
+
+func (ew *etagResponseWriter) Write(b []byte) (int, error) {
+	if ew.status == 0 {
+		ew.status = http.StatusOK
+	}
+	if !ew.passthrough && ew.buf.Len()+len(b) > ew.maxBodySize {
+		if err := ew.startPassthrough(); err != nil {
+			return 0, err
+		}
+	}
+	if ew.passthrough {
+		n, err := ew.ResponseWriter.Write(b)
+		ew.written += int64(n)
+		return n, err
+	}
+	return ew.buf.Write(b)
+}
+
+// Flush 流式响应无法预先计算 ETag，直接转为透传
+func (ew *etagResponseWriter) Flush() {
+	if !ew.passthrough {
+		if ew.status == 0 {
+			ew.status = http.StatusOK
+		}
+		if err := ew.startPassthrough(); err != nil {
+			return
+		}
+	}
+	if f, ok := ew.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (ew *etagResponseWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
+
+func (ew *etagResponseWriter) startPassthrough() error {
+	ew.passthrough = true
+	ew.ResponseWriter.WriteHeader(ew.status)
+	n, err := ew.ResponseWriter.Write(ew.buf.Bytes())
+	ew.written += int64(n)
+	ew.buf.Reset()
+	return err
+}
+
+// etagMatch 按弱比较规则判断 If-None-Match 是否包含 etag
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
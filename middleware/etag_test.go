@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lemonc7/zest"
+)
+
+func TestETagGetHeadParity(t *testing.T) {
+	z := zest.New()
+	z.Use(ETag())
+	z.GET("/", func(c *zest.Context) error {
+		return c.String(http.StatusOK, "hello world")
+	})
+
+	etag := z.TestRequest(http.MethodGet, "/", nil).Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("GET response has no ETag")
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"get", http.MethodGet, "", http.StatusOK, "hello world"},
+		{"head", http.MethodHead, "", http.StatusOK, ""},
+		{"get not modified", http.MethodGet, etag, http.StatusNotModified, ""},
+		{"head not modified", http.MethodHead, etag, http.StatusNotModified, ""},
+		{"head other etag", http.MethodHead, `"other"`, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := z.Test(req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("ETag"); got != etag {
+				t.Errorf("ETag = %q, want %q", got, etag)
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lemonc7/zest"
+)
+
+// GzipConfig Gzip 中间件配置
+type GzipConfig struct {
+	// Skip 返回 true 时跳过压缩
+	Skip func(c *zest.Context) bool
+	// Level 压缩级别，取值同 compress/gzip
+	// 默认 gzip.DefaultCompression
+	Level int
+	// MinLength 响应体小于该字节数时不压缩，压缩太小的响应得不偿失
+	// 默认 1024
+	MinLength int
+	// ExcludedContentTypes 不压缩的 Content-Type 前缀，通常是已经压缩过的格式
+	// 默认跳过图片、音视频和常见的压缩包格式（image/svg+xml 除外）
+	ExcludedContentTypes []string
+}
+
+// DefaultGzipConfig 默认配置
+var DefaultGzipConfig = GzipConfig{
+	Level:                gzip.DefaultCompression,
+	MinLength:            1024,
+	ExcludedContentTypes: defaultExcludedContentTypes,
+}
+
+// defaultExcludedContentTypes Gzip 和 Brotli 默认不压缩的 Content-Type
+var defaultExcludedContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+}
+
+// Gzip 返回 gzip 响应压缩中间件
+// 只在客户端的 Accept-Encoding 包含 gzip 时生效，并设置 Content-Encoding 和 Vary 响应头；Accept-Encoding: identity 或 gzip;q=0 时不压缩
+// 解压 gzip 请求体由 Decompress 负责，两者可以同时安装
+// c.Response().Size 记录的是压缩后实际发送的字节数
+func Gzip(config ...GzipConfig) zest.MiddlewareFunc {
+	cfg := DefaultGzipConfig
+	if len(config) > 0 {
+		userCfg := config[0]
+		if userCfg.Skip != nil {
+			cfg.Skip = userCfg.Skip
+		}
+		if userCfg.Level != 0 {
+			cfg.Level = userCfg.Level
+		}
+		if userCfg.MinLength > 0 {
+			cfg.MinLength = userCfg.MinLength
+		}
+		if len(userCfg.ExcludedContentTypes) > 0 {
+			cfg.ExcludedContentTypes = userCfg.ExcludedContentTypes
+		}
+	}
+
+	// 校验压缩级别，非法配置在启动时就暴露出来
+	if _, err := gzip.NewWriterLevel(io.Discard, cfg.Level); err != nil {
+		panic(err)
+	}
+
+	pool := sync.Pool{
+		New: func() any {
+			w, _ := gzip.NewWriterLevel(io.Discard, cfg.Level)
+			return w
+		},
+	}
+
+	return compress(compressOptions{
+		encoding:             "gzip",
+		skip:                 cfg.Skip,
+		minLength:            cfg.MinLength,
+		excludedContentTypes: cfg.ExcludedContentTypes,
+		acquire:              func() Compressor { return pool.Get().(*gzip.Writer) },
+		release:              func(w Compressor) { pool.Put(w) },
+	})
+}
+
+// Compressor 压缩编码器需要实现的方法，*gzip.Writer 和 github.com/andybalholm/brotli 的 *brotli.Writer 都满足该接口
+type Compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+type compressOptions struct {
+	encoding             string
+	skip                 func(c *zest.Context) bool
+	minLength            int
+	excludedContentTypes []string
+	acquire              func() Compressor
+	release              func(Compressor)
+}
+
+// compressingKey 设置后内层的压缩中间件不再压缩
+const compressingKey = "middleware.compressing"
+
+// compress 响应压缩中间件的通用实现
+func compress(opts compressOptions) zest.MiddlewareFunc {
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if opts.skip != nil && opts.skip(c) {
+				return next(c)
+			}
+
+			// 外层的压缩中间件已经在压缩，避免重复压缩
+			if compressing, _ := zest.Get[bool](c, compressingKey); compressing {
+				return next(c)
+			}
+
+			res := c.Response()
+			// 同时安装 Brotli 和 Gzip 时只添加一次
+			if !slices.Contains(res.Header().Values(zest.HeaderVary), zest.HeaderAcceptEncoding) {
+				res.Header().Add(zest.HeaderVary, zest.HeaderAcceptEncoding)
+			}
+
+			// HEAD 请求没有响应体，不需要压缩
+			if c.Request.Method == http.MethodHead ||
+				!acceptsEncoding(c.Request.Header.Get(zest.HeaderAcceptEncoding), opts.encoding) {
+				return next(c)
+			}
+			c.Set(compressingKey, true)
+
+			cw := &compressResponseWriter{
+				ResponseWriter: res.ResponseWriter,
+				opts:           &opts,
+			}
+			res.ResponseWriter = cw
+
+			err := next(c)
+
+			cw.finish()
+			res.ResponseWriter = cw.ResponseWriter
+			// 让日志等中间件看到实际发送的字节数
+			res.Size = cw.written
+
+			return err
+		}
+	}
+}
+
+// compressResponseWriter 先缓冲 minLength 字节再决定是否压缩
+type compressResponseWriter struct {
+	http.ResponseWriter
+	opts *compressOptions
+
+	status      int
+	buf         bytes.Buffer
+	decided     bool
+	compressing bool
+	w           Compressor
+	written     int64
+}
+
+func (cw *compressResponseWriter) WriteHeader(code int) {
+	if cw.status == 0 {
+		cw.status = code
+	}
+}
+
+func (cw *compressResponseWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if cw.decided {
+		if cw.compressing {
+			return cw.w.Write(b)
+		}
+		return cw.writeRaw(b)
+	}
+
+	header := cw.Header()
+	if header.Get(zest.HeaderContentType) == "" {
+		header.Set(zest.HeaderContentType, http.DetectContentType(b))
+	}
+	// 已经编码过的响应或不适合压缩的类型直接透传
+	if header.Get(zest.HeaderContentEncoding) != "" || !cw.compressible(header.Get(zest.HeaderContentType)) {
+		if err := cw.decide(false); err != nil {
+			return 0, err
+		}
+		return cw.writeRaw(b)
+	}
+
+	cw.buf.Write(b)
+	if cw.buf.Len() >= cw.opts.minLength {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush 流式响应不再等待 minLength，立即开始压缩并刷新
+func (cw *compressResponseWriter) Flush() {
+	if !cw.decided {
+		compressing := cw.Header().Get(zest.HeaderContentEncoding) == "" &&
+			cw.compressible(cw.Header().Get(zest.HeaderContentType))
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		if err := cw.decide(compressing); err != nil {
+			return
+		}
+	}
+	if cw.compressing {
+		_ = cw.w.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// decide 写入响应头，并把缓冲区中的数据按是否压缩写出
+func (cw *compressResponseWriter) decide(compressing bool) error {
+	cw.decided = true
+	cw.compressing = compressing
+
+	if compressing {
+		header := cw.Header()
+		header.Set(zest.HeaderContentEncoding, cw.opts.encoding)
+		// 压缩后长度改变，由服务器自动分块或计算
+		header.Del(zest.HeaderContentLength)
+
+		cw.w = cw.opts.acquire()
+		cw.w.Reset(countingWriter{cw})
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if cw.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if compressing {
+		_, err = cw.w.Write(cw.buf.Bytes())
+	} else {
+		_, err = cw.writeRaw(cw.buf.Bytes())
+	}
+	cw.buf.Reset()
+	return err
+}
+
+// finish 在处理链结束后调用，写出剩余数据并归还编码器
+func (cw *compressResponseWriter) finish() {
+	if !cw.decided {
+		// 没有写入过任何内容也没有设置状态码，交给后续的错误处理器处理
+		if cw.status == 0 && cw.buf.Len() == 0 {
+			return
+		}
+		// 响应体不足 minLength，不压缩
+		_ = cw.decide(false)
+	}
+	if cw.compressing {
+		_ = cw.w.Close()
+		cw.w.Reset(io.Discard)
+		cw.opts.release(cw.w)
+		cw.w = nil
+	}
+}
+
+func (cw *compressResponseWriter) writeRaw(b []byte) (int, error) {
+	n, err := cw.ResponseWriter.Write(b)
+	cw.written += int64(n)
+	return n, err
+}
+
+func (cw *compressResponseWriter) compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	if strings.HasPrefix(contentType, "image/svg+xml") {
+		return true
+	}
+	for _, excluded := range cw.opts.excludedContentTypes {
+		if strings.HasPrefix(contentType, excluded) {
+			return false
+		}
+	}
+	return true
+}
+
+// countingWriter 统计压缩后写入底层 ResponseWriter 的字节数
+type countingWriter struct {
+	cw *compressResponseWriter
+}
+
+func (w countingWriter) Write(b []byte) (int, error) {
+	return w.cw.writeRaw(b)
+}
+
+// acceptsEncoding 判断 Accept-Encoding 是否接受指定编码，q=0 表示明确拒绝
+func acceptsEncoding(header, encoding string) bool {
+	accepted := false
+	for part := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != encoding && name != "*" {
+			continue
+		}
+
+		q := 1.0
+		if k, v, ok := strings.Cut(params, "="); ok && strings.TrimSpace(k) == "q" {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = f
+			}
+		}
+		// 明确列出的编码优先于通配符
+		if name == encoding {
+			return q > 0
+		}
+		accepted = q > 0
+	}
+	return accepted
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lemonc7/zest"
+)
+
+// HealthConfig 健康检查中间件配置
+type HealthConfig struct {
+	// LivenessPath 存活检查路径，只要进程能处理请求就返回 200
+	// 可选，默认 "/healthz"
+	LivenessPath string
+	// ReadinessPath 就绪检查路径，执行 Checks 中的所有检查，任意一个失败返回 503
+	// 可选，默认 "/readyz"
+	ReadinessPath string
+	// Checks 就绪检查项，key 为检查名称，如 {"db": db.PingContext}
+	Checks map[string]func(ctx context.Context) error
+	// Timeout 所有检查的总超时时间，检查并行执行
+	// 可选，默认 5 秒
+	Timeout time.Duration
+	// LogLiveness 是否让 Logger 和 Prometheus 记录存活检查请求
+	// 存活检查通常被频繁调用，默认 false，即不记录
+	LogLiveness bool
+}
+
+// DefaultHealthConfig 默认配置
+var DefaultHealthConfig = HealthConfig{
+	LivenessPath:  "/healthz",
+	ReadinessPath: "/readyz",
+	Timeout:       5 * time.Second,
+}
+
+// skipObserveKey 设置后 Logger 和 Prometheus 不记录该请求，通过 SkipObserve 和 ObserveSkipped 访问
+const skipObserveKey = "middleware.skipObserve"
+
+// SkipObserve 标记当前请求不被 Logger 和 middleware/prometheus 记录，如健康检查、探针等高频请求
+// 在处理链的任意位置调用都有效，与中间件的注册顺序无关
+func SkipObserve(c *zest.Context) {
+	c.Set(skipObserveKey, true)
+}
+
+// ObserveSkipped 判断当前请求是否通过 SkipObserve 标记为不记录
+func ObserveSkipped(c *zest.Context) bool {
+	skip, _ := zest.Get[bool](c, skipObserveKey)
+	return skip
+}
+
+// HealthCheck 返回健康检查中间件，拦截 LivenessPath 和 ReadinessPath 的 GET/HEAD 请求，其余请求交给后续处理
+// 存活检查返回 {"status":"ok"}；就绪检查返回 {"status":"ok|unavailable","checks":{"db":"ok","cache":"<错误信息>"}}
+// 存活检查请求默认不会被 Logger 和 Prometheus 记录，与中间件的注册顺序无关
+func HealthCheck(config HealthConfig) zest.MiddlewareFunc {
+	if config.LivenessPath == "" {
+		config.LivenessPath = DefaultHealthConfig.LivenessPath
+	}
+	if config.ReadinessPath == "" {
+		config.ReadinessPath = DefaultHealthConfig.ReadinessPath
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultHealthConfig.Timeout
+	}
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+				return next(c)
+			}
+
+			switch c.Request.URL.Path {
+			case config.LivenessPath:
+				if !config.LogLiveness {
+					SkipObserve(c)
+				}
+				return c.JSON(http.StatusOK, zest.Map{"status": "ok"})
+			case config.ReadinessPath:
+				results, ok := runChecks(c.Context(), config.Checks, config.Timeout)
+				if !ok {
+					return c.JSON(http.StatusServiceUnavailable, zest.Map{"status": "unavailable", "checks": results})
+				}
+				return c.JSON(http.StatusOK, zest.Map{"status": "ok", "checks": results})
+			}
+			return next(c)
+		}
+	}
+}
+
+// runChecks 并行执行所有检查，返回每项的结果和是否全部通过
+// 超时的检查记为失败，不会等待它返回
+func runChecks(ctx context.Context, checks map[string]func(ctx context.Context) error, timeout time.Duration) (map[string]string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	results := make(map[string]string, len(checks))
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := "ok"
+			if err := check(ctx); err != nil {
+				status = err.Error()
+			}
+			mu.Lock()
+			results[name] = status
+			mu.Unlock()
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	ok := true
+	for name := range checks {
+		if _, finished := results[name]; !finished {
+			results[name] = ctx.Err().Error()
+		}
+		if results[name] != "ok" {
+			ok = false
+		}
+	}
+	// 返回副本，超时后仍在运行的检查可能继续写入 results
+	return maps.Clone(results), ok
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lemonc7/zest"
+)
+
+func TestHealthCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		checks     map[string]func(ctx context.Context) error
+		wantStatus int
+		wantChecks map[string]string
+		// wantLogged 请求是否被 Logger 记录
+		wantLogged bool
+	}{
+		{
+			name:       "liveness",
+			target:     "/healthz",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "readiness ok",
+			target: "/readyz",
+			checks: map[string]func(ctx context.Context) error{
+				"db": func(ctx context.Context) error { return nil },
+			},
+			wantStatus: http.StatusOK,
+			wantChecks: map[string]string{"db": "ok"},
+			wantLogged: true,
+		},
+		{
+			name:   "readiness failed",
+			target: "/readyz",
+			checks: map[string]func(ctx context.Context) error{
+				"db":    func(ctx context.Context) error { return nil },
+				"cache": func(ctx context.Context) error { return errors.New("connection refused") },
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"db": "ok", "cache": "connection refused"},
+			wantLogged: true,
+		},
+		{
+			name:   "check ignores timeout",
+			target: "/readyz",
+			checks: map[string]func(ctx context.Context) error{
+				"db": func(ctx context.Context) error { return nil },
+				"slow": func(ctx context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"db": "ok", "slow": context.DeadlineExceeded.Error()},
+			wantLogged: true,
+		},
+		{
+			name:       "other paths",
+			target:     "/users",
+			wantStatus: http.StatusNotFound,
+			wantLogged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logged := false
+			z := zest.New()
+			// Logger 注册在 HealthCheck 之前，仍然不会记录存活检查
+			z.Use(Logger(LoggerConfig{
+				Output: io.Discard,
+				Formatter: func(p LogParam) string {
+					logged = true
+					return ""
+				},
+			}))
+			z.Use(HealthCheck(HealthConfig{Checks: tt.checks, Timeout: 50 * time.Millisecond}))
+
+			start := time.Now()
+			rec := z.TestRequest(http.MethodGet, tt.target, nil)
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("request took %v, want it to return after Timeout", elapsed)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %q", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if logged != tt.wantLogged {
+				t.Errorf("logged = %v, want %v", logged, tt.wantLogged)
+			}
+			if tt.wantChecks == nil {
+				return
+			}
+
+			var body struct {
+				Status string            `json:"status"`
+				Checks map[string]string `json:"checks"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+			}
+			wantStatus := "ok"
+			if tt.wantStatus != http.StatusOK {
+				wantStatus = "unavailable"
+			}
+			if body.Status != wantStatus {
+				t.Errorf("status field = %q, want %q", body.Status, wantStatus)
+			}
+			if len(body.Checks) != len(tt.wantChecks) {
+				t.Errorf("checks = %v, want %v", body.Checks, tt.wantChecks)
+			}
+			for name, want := range tt.wantChecks {
+				if body.Checks[name] != want {
+					t.Errorf("checks[%q] = %q, want %q", name, body.Checks[name], want)
+				}
+			}
+		})
+	}
+}
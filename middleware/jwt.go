@@ -13,34 +13,92 @@ type JWTer interface {
 	Parse(tokenString string) (map[string]any, error)
 }
 
-// JWT 返回 JWT 认证中间件
-// 只支持 "Authorization: Bearer <token>" 格式
+// JWTConfig JWT 中间件配置
+type JWTConfig struct {
+	// JWTer 解析和验证 token，必填
+	JWTer JWTer
+	// Skip 返回 true 时跳过认证
+	Skip func(c *zest.Context) bool
+	// TokenLookup token 的来源，格式为 "<source>:<name>"，多个来源用逗号分隔，按顺序查找
+	// source 支持 header、cookie、query，例如 "header:Authorization,cookie:jwt"
+	// 可选，默认 "header:Authorization"
+	TokenLookup string
+	// AuthScheme 从 header 读取 token 时要求的前缀
+	// 可选，默认 "Bearer"
+	AuthScheme string
+}
+
+// DefaultJWTConfig 默认配置
+var DefaultJWTConfig = JWTConfig{
+	TokenLookup: "header:" + zest.HeaderAuthorization,
+	AuthScheme:  "Bearer",
+}
+
+// JWT 返回 JWT 认证中间件，使用 DefaultJWTConfig 从 "Authorization: Bearer <token>" 读取 token
+// 需要从 cookie、query 或其他请求头读取时，使用 JWTWithConfig 并设置 TokenLookup（如 "header:Authorization,cookie:jwt"）
 // skipper 可选参数：返回 true 时跳过认证
 func JWT(j JWTer, skipper ...func(*zest.Context) bool) zest.MiddlewareFunc {
-	skip := func(c *zest.Context) bool { return false }
-	if len(skipper) > 0 && skipper[0] != nil {
-		skip = skipper[0]
+	cfg := JWTConfig{JWTer: j}
+	if len(skipper) > 0 {
+		cfg.Skip = skipper[0]
+	}
+	return JWTWithConfig(cfg)
+}
+
+// JWTWithConfig 返回可以配置 token 来源的 JWT 认证中间件
+// 所有来源都没有 token 时返回 401 missing token；header 中的前缀不正确时返回 401 invalid token format
+func JWTWithConfig(config JWTConfig) zest.MiddlewareFunc {
+	if config.JWTer == nil {
+		panic("zest: jwt middleware requires a JWTer")
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = DefaultJWTConfig.TokenLookup
+	}
+	if config.AuthScheme == "" {
+		config.AuthScheme = DefaultJWTConfig.AuthScheme
+	}
+
+	extractors := make([]tokenExtractor, 0, 1)
+	for lookup := range strings.SplitSeq(config.TokenLookup, ",") {
+		source, name, ok := strings.Cut(strings.TrimSpace(lookup), ":")
+		if !ok || name == "" {
+			panic("zest: invalid jwt token lookup " + lookup)
+		}
+		switch source {
+		case "header":
+			extractors = append(extractors, tokenFromHeader(name, config.AuthScheme))
+		case "cookie":
+			extractors = append(extractors, tokenFromCookie(name))
+		case "query":
+			extractors = append(extractors, tokenFromQuery(name))
+		default:
+			panic("zest: unsupported jwt token source " + source)
+		}
 	}
 
 	return func(next zest.HandlerFunc) zest.HandlerFunc {
 		return func(c *zest.Context) error {
 			// 跳过认证
-			if skip(c) {
+			if config.Skip != nil && config.Skip(c) {
 				return next(c)
 			}
 
-			authHeader := c.Request.Header.Get("Authorization")
-			if authHeader == "" {
-				return zest.NewHTTPError(http.StatusUnauthorized, "missing token")
+			var tokenString string
+			for _, extract := range extractors {
+				token, err := extract(c)
+				if err != nil {
+					return err
+				}
+				if token != "" {
+					tokenString = token
+					break
+				}
 			}
-
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				return zest.NewHTTPError(http.StatusUnauthorized, "invalid token format")
+			if tokenString == "" {
+				return zest.NewHTTPError(http.StatusUnauthorized, "missing token")
 			}
 
-			tokenString := parts[1]
-			claims, err := j.Parse(tokenString)
+			claims, err := config.JWTer.Parse(tokenString)
 			if err != nil {
 				return zest.NewHTTPError(http.StatusUnauthorized, err.Error())
 			}
@@ -54,3 +112,37 @@ func JWT(j JWTer, skipper ...func(*zest.Context) bool) zest.MiddlewareFunc {
 		}
 	}
 }
+
+// tokenExtractor 从请求中提取 token，没有找到时返回空字符串
+type tokenExtractor func(c *zest.Context) (string, error)
+
+func tokenFromHeader(header, scheme string) tokenExtractor {
+	return func(c *zest.Context) (string, error) {
+		authHeader := c.Request.Header.Get(header)
+		if authHeader == "" {
+			return "", nil
+		}
+
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || parts[0] != scheme {
+			return "", zest.NewHTTPError(http.StatusUnauthorized, "invalid token format")
+		}
+		return parts[1], nil
+	}
+}
+
+func tokenFromCookie(name string) tokenExtractor {
+	return func(c *zest.Context) (string, error) {
+		cookie, err := c.Cookie(name)
+		if err != nil {
+			return "", nil
+		}
+		return cookie.Value, nil
+	}
+}
+
+func tokenFromQuery(name string) tokenExtractor {
+	return func(c *zest.Context) (string, error) {
+		return c.Query(name), nil
+	}
+}
@@ -1,17 +1,24 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net/http"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 	"time"
 
+	// 内嵌时区数据，用户通过 time.LoadLocation 设置 TZ 时不依赖系统的 zoneinfo
 	_ "time/tzdata"
 
 	"github.com/lemonc7/zest"
+	"golang.org/x/term"
 )
 
 // LoggerConfig 日志中间件配置
@@ -19,14 +26,32 @@ type LoggerConfig struct {
 	// Skip 判断是否跳过日志记录的函数
 	// 返回 true 则不记录
 	Skip func(c *zest.Context) bool
+	// SkipPaths 不记录日志的路径，如 "/healthz"
+	// 以 * 结尾表示前缀匹配（"/static/*"），包含其他通配符时按 path.Match 匹配（"/*.ico"）
+	// 与 Skip 同时设置时，任意一个匹配即跳过
+	SkipPaths []string
 	// Formatter 自定义日志格式化函数
 	// 接收 LogParam 参数，返回格式化后的字符串
 	Formatter func(param LogParam) string
 	// Output 日志输出目标
 	// 默认为 os.Stdout
 	Output io.Writer
-	// 时区，默认为Asia/Shanghai
+	// TZ 日志时间使用的时区，nil 或 time.UTC 表示 UTC，time.Local 表示服务器本地时区
+	// 默认为 UTC
 	TZ *time.Location
+	// TimeFormat 默认日志格式中的时间格式，与 time.Format 的 layout 相同
+	// 默认为 "2006/01/02 15:04:05"
+	TimeFormat string
+	// SlogLogger 设置后通过 slog 记录结构化日志，Formatter 和 Output 将被忽略
+	// 日志级别由状态码决定：5xx 为 Error，4xx 为 Warn，其余为 Info
+	SlogLogger *slog.Logger
+	// LogStart 在调用 Handler 之前额外输出一行开始日志（方法、路径、请求 ID），请求完成后照常输出完成日志
+	// 用于排查一直没有返回、因而没有完成日志的请求；开始日志不经过 Formatter
+	// 默认 false，每个请求只输出一行
+	LogStart bool
+	// DisableColor 关闭默认日志格式中的 ANSI 颜色代码，自定义 Formatter 不受影响
+	// Output 不是终端（如文件、管道、日志收集器）时自动关闭，默认输出到终端时保留颜色
+	DisableColor bool
 }
 
 // LogParam 日志参数，包含请求的所有关键信息
@@ -34,21 +59,27 @@ type LogParam struct {
 	TimeStamp time.Time     // 请求完成时间
 	Status    int           // HTTP 状态码
 	Latency   time.Duration // 请求耗时
+	BytesIn   int64         // 请求体大小（字节），没有 Content-Length 时为实际读取的字节数
 	Size      int64         // 响应大小（字节）
 	RequestID string        // 请求唯一 ID
 	ClientIP  string        // 客户端 IP
 	Method    string        // HTTP 方法（GET/POST/etc）
 	Path      string        // 请求路径（包含 query 参数）
+	Route     string        // 匹配到的路由模式，如 /users/{id}，未匹配时为空
 	Error     error         // 如果 handler 返回了错误
 }
 
 // DefaultLoggerConfig 默认日志配置
 var DefaultLoggerConfig = LoggerConfig{
-	Formatter: defaultLogFormatter,
-	Output:    os.Stdout,
-	TZ:        mustLoadLocation("Asia/Shanghai"),
+	Formatter:  defaultLogFormatter,
+	Output:     os.Stdout,
+	TZ:         time.UTC,
+	TimeFormat: defaultLogTimeFormat,
 }
 
+// defaultLogTimeFormat 默认日志格式中的时间格式
+const defaultLogTimeFormat = "2006/01/02 15:04:05"
+
 const (
 	cyan    = "\033[96m"
 	green   = "\033[92m"
@@ -60,7 +91,13 @@ const (
 )
 
 // defaultLogFormatter 默认的日志格式化函数
+// Logger 使用默认格式时会换成按 LoggerConfig 的 TimeFormat 和颜色设置生成的同格式函数
 func defaultLogFormatter(param LogParam) string {
+	return formatLog(param, true, defaultLogTimeFormat)
+}
+
+// formatLog 按默认格式输出一条日志，color 为 false 时不输出 ANSI 颜色代码
+func formatLog(param LogParam, color bool, timeFormat string) string {
 	var b strings.Builder
 	b.Grow(128) // 预分配 buffer，避免由于扩容产生的多次内存分配
 
@@ -82,26 +119,24 @@ func defaultLogFormatter(param LogParam) string {
 	b.WriteString(" ")
 
 	// Time
-	b.WriteString(param.TimeStamp.Format("2006/01/02 15:04:05"))
+	b.WriteString(param.TimeStamp.Format(timeFormat))
 	b.WriteString(" | ")
 
 	// Status with Color
-	b.WriteString(getStatusColor(param.Status))
-	b.WriteString(strconv.Itoa(param.Status)) // 使用 Itoa 替代 fmt.Sprintf("%3d")
-	b.WriteString(reset)
+	writeColored(&b, color, getStatusColor(param.Status), strconv.Itoa(param.Status)) // 使用 Itoa 替代 fmt.Sprintf("%3d")
 	b.WriteString(" | ")
 
 	// Method with Color
-	b.WriteString(getMethodColor(param.Method))
-	b.WriteString(param.Method)
-	b.WriteString(reset)
+	writeColored(&b, color, getMethodColor(param.Method), param.Method)
 	b.WriteString(" | ")
 
 	// Latency
 	b.WriteString(formatLatency(param.Latency))
 	b.WriteString(" | ")
 
-	// Size
+	// Size：请求体 → 响应体
+	b.WriteString(formatSize(param.BytesIn))
+	b.WriteString(" → ")
 	b.WriteString(formatSize(param.Size))
 	b.WriteString(" | ")
 
@@ -115,16 +150,186 @@ func defaultLogFormatter(param LogParam) string {
 	// Error
 	if param.Error != nil {
 		b.WriteString(" | ")
-		b.WriteString(red)
-		b.WriteString("Error: ")
-		b.WriteString(param.Error.Error())
-		b.WriteString(reset)
+		writeColored(&b, color, red, "Error: "+param.Error.Error())
 	}
 
 	b.WriteString("\n")
 	return b.String()
 }
 
+// writeColored 写入 s，color 为 true 时用 code 着色
+func writeColored(b *strings.Builder, color bool, code, s string) {
+	if !color {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(code)
+	b.WriteString(s)
+	b.WriteString(reset)
+}
+
+// isTerminal 判断 w 是否为终端，只有 *os.File 才可能是终端
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// JSONLogFormatter 以 JSON 格式输出日志，每个请求一行，不包含颜色代码
+// 用法：middleware.Logger(middleware.LoggerConfig{Formatter: middleware.JSONLogFormatter})
+func JSONLogFormatter(param LogParam) string {
+	entry := struct {
+		Time      string  `json:"time"`
+		Status    int     `json:"status"`
+		LatencyMS float64 `json:"latency_ms"`
+		BytesIn   int64   `json:"bytes_in"`
+		Size      int64   `json:"size"`
+		RequestID string  `json:"request_id,omitempty"`
+		ClientIP  string  `json:"client_ip"`
+		Method    string  `json:"method"`
+		Path      string  `json:"path"`
+		Route     string  `json:"route,omitempty"`
+		Error     string  `json:"error,omitempty"`
+	}{
+		Time:      param.TimeStamp.Format(time.RFC3339),
+		Status:    param.Status,
+		LatencyMS: float64(param.Latency) / float64(time.Millisecond),
+		BytesIn:   param.BytesIn,
+		Size:      param.Size,
+		RequestID: param.RequestID,
+		ClientIP:  param.ClientIP,
+		Method:    param.Method,
+		Path:      param.Path,
+		Route:     param.Route,
+	}
+	if param.Error != nil {
+		entry.Error = param.Error.Error()
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return ""
+	}
+	return string(b) + "\n"
+}
+
+// logStart 输出请求的开始日志
+func logStart(c *zest.Context, cfg LoggerConfig, path, raw string, color bool) {
+	if raw != "" {
+		path = path + "?" + raw
+	}
+	rid := zest.MustGet[string](c, "requestID")
+
+	if cfg.SlogLogger != nil {
+		cfg.SlogLogger.LogAttrs(c.Context(), slog.LevelInfo, "request started",
+			slog.String("request_id", rid),
+			slog.String("method", c.Method),
+			slog.String("path", path),
+		)
+		return
+	}
+
+	if rid == "" {
+		rid = "-"
+	} else if len(rid) > 8 {
+		rid = rid[:8]
+	}
+	var b strings.Builder
+	b.WriteString("[")
+	b.WriteString(rid)
+	b.WriteString("] ⏳ ")
+	b.WriteString(time.Now().In(cfg.TZ).Format(cfg.TimeFormat))
+	b.WriteString(" | ")
+	writeColored(&b, color, getMethodColor(c.Method), c.Method)
+	b.WriteString(" | ")
+	b.WriteString(path)
+	b.WriteString("\n")
+	fmt.Fprint(cfg.Output, b.String())
+}
+
+// logSlog 通过 slog 记录一条请求日志
+func logSlog(l *slog.Logger, ctx context.Context, param LogParam) {
+	level := slog.LevelInfo
+	switch {
+	case param.Status >= 500:
+		level = slog.LevelError
+	case param.Status >= 400:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.Int("status", param.Status),
+		slog.Float64("latency_ms", float64(param.Latency)/float64(time.Millisecond)),
+		slog.Int64("bytes_in", param.BytesIn),
+		slog.Int64("size", param.Size),
+		slog.String("request_id", param.RequestID),
+		slog.String("client_ip", param.ClientIP),
+		slog.String("method", param.Method),
+		slog.String("path", param.Path),
+		slog.String("route", param.Route),
+	}
+	if param.Error != nil {
+		attrs = append(attrs, slog.String("error", param.Error.Error()))
+	}
+	l.LogAttrs(ctx, level, "request", attrs...)
+}
+
+// skipPaths 将 SkipPaths 编译成 Skip 函数，并与已有的 skip 组合
+func skipPaths(skip func(c *zest.Context) bool, paths []string) func(c *zest.Context) bool {
+	exact := make(map[string]struct{})
+	var prefixes, globs []string
+	for _, p := range paths {
+		switch {
+		case strings.HasSuffix(p, "*") && !strings.ContainsAny(p[:len(p)-1], "*?["):
+			prefixes = append(prefixes, strings.TrimSuffix(p, "*"))
+		case strings.ContainsAny(p, "*?["):
+			globs = append(globs, p)
+		default:
+			exact[p] = struct{}{}
+		}
+	}
+
+	return func(c *zest.Context) bool {
+		if skip != nil && skip(c) {
+			return true
+		}
+		p := c.Request.URL.Path
+		if _, ok := exact[p]; ok {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(p, prefix) {
+				return true
+			}
+		}
+		for _, glob := range globs {
+			if ok, _ := path.Match(glob, p); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// countingReadCloser 统计读取的字节数
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// bytesIn 优先使用 Content-Length，分块请求（-1）使用实际读取的字节数
+func bytesIn(contentLength, read int64) int64 {
+	if contentLength >= 0 {
+		return contentLength
+	}
+	return read
+}
+
 func formatSize(s int64) string {
 	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
 	size := float64(s)
@@ -170,6 +375,24 @@ func Logger(config ...LoggerConfig) zest.MiddlewareFunc {
 		if userCfg.TZ != nil {
 			cfg.TZ = userCfg.TZ
 		}
+		if userCfg.TimeFormat != "" {
+			cfg.TimeFormat = userCfg.TimeFormat
+		}
+		cfg.SlogLogger = userCfg.SlogLogger
+		if len(userCfg.SkipPaths) > 0 {
+			cfg.Skip = skipPaths(cfg.Skip, userCfg.SkipPaths)
+		}
+		cfg.DisableColor = userCfg.DisableColor
+		cfg.LogStart = userCfg.LogStart
+	}
+	color := !cfg.DisableColor && isTerminal(cfg.Output)
+
+	// 使用默认格式时按配置的时间格式输出，输出目标不是终端或者显式关闭颜色则去掉颜色代码
+	if len(config) == 0 || config[0].Formatter == nil {
+		timeFormat := cfg.TimeFormat
+		cfg.Formatter = func(param LogParam) string {
+			return formatLog(param, color, timeFormat)
+		}
 	}
 
 	// 返回实际的中间件函数
@@ -186,57 +409,94 @@ func Logger(config ...LoggerConfig) zest.MiddlewareFunc {
 			path := c.Request.URL.Path
 			raw := c.Request.URL.RawQuery
 
-			// ============ 步骤 3: 执行实际的 Handler ============
-			err := next(c)
-
-			// ============ 步骤 4: 如果有错误，先调用全局错误处理器 ============
-			// 这样可以确保日志中记录的 status code 是正确的错误状态码
-			if err != nil {
-				c.Error(err)
+			if cfg.LogStart {
+				logStart(c, cfg, path, raw, color)
 			}
 
-			// ============ 步骤 5: 拼接完整路径（包含查询参数）============
-			if raw != "" {
-				path = path + "?" + raw
+			// 统计实际读取的请求体字节数，用于没有 Content-Length 的分块请求
+			body := &countingReadCloser{ReadCloser: c.Request.Body}
+			if c.Request.Body != nil && c.Request.Body != http.NoBody {
+				c.Request.Body = body
 			}
 
-			// ============ 步骤 6: 收集日志参数 ============
-			// 尝试获取 RequestID
-			var rid string
-			if v := c.Get("requestID"); v != nil {
-				if id, ok := v.(string); ok {
-					rid = id
+			// 输出一条日志，正常返回和 panic 时共用
+			emit := func(status int, err error) {
+				// HealthCheck 等中间件可以要求不记录该请求
+				if ObserveSkipped(c) {
+					return
+				}
+
+				// ============ 步骤 5: 拼接完整路径（包含查询参数）============
+				path := path
+				if raw != "" {
+					path = path + "?" + raw
+				}
+
+				// ============ 步骤 6: 收集日志参数 ============
+				// 尝试获取 RequestID
+				rid := zest.MustGet[string](c, "requestID")
+
+				// 如果有错误，尝试解包获取内部错误
+				var internalErr error
+				var he *zest.HTTPError
+				if errors.As(err, &he) && he.Unwrap() != nil {
+					internalErr = he.Unwrap()
+				} else {
+					internalErr = err
+				}
+
+				param := LogParam{
+					TimeStamp: time.Now().In(cfg.TZ),
+					Status:    status,
+					Latency:   time.Since(start),
+					BytesIn:   bytesIn(c.Request.ContentLength, body.n),
+					Size:      c.Response().Size,
+					RequestID: rid,
+					ClientIP:  c.ClientIP(),
+					Method:    c.Method,
+					Path:      path,
+					Route:     c.RoutePattern(),
+					Error:     internalErr,
 				}
-			}
 
-			// 如果有错误，尝试解包获取内部错误
-			var internalErr error
-			var he *zest.HTTPError
-			if errors.As(err, &he) && he.Unwrap() != nil {
-				internalErr = he.Unwrap()
-			} else {
-				internalErr = err
+				// ============ 步骤 7: 格式化并输出日志 ============
+				if cfg.SlogLogger != nil {
+					logSlog(cfg.SlogLogger, c.Context(), param)
+				} else {
+					logStr := cfg.Formatter(param)
+					fmt.Fprint(cfg.Output, logStr)
+				}
 			}
 
-			param := LogParam{
-				TimeStamp: time.Now().In(cfg.TZ),
-				Status:    c.Response().Status,
-				Latency:   time.Since(start),
-				Size:      c.Response().Size,
-				RequestID: rid,
-				ClientIP:  c.ClientIP(),
-				Method:    c.Method,
-				Path:      path,
-				Error:     internalErr,
+			// Recovery 注册在 Logger 外层时，panic 会直接穿过 Logger
+			// 这里先按 500 记录再继续抛出，由外层的 Recovery 生成响应，两种注册顺序都能记录到 panic
+			defer func() {
+				if r := recover(); r != nil {
+					if e, ok := r.(error); !ok || !errors.Is(e, http.ErrAbortHandler) {
+						status := http.StatusInternalServerError
+						if c.Response().Committed {
+							status = c.Response().Status
+						}
+						emit(status, fmt.Errorf("panic: %v", r))
+					}
+					panic(r)
+				}
+			}()
+
+			// ============ 步骤 3: 执行实际的 Handler ============
+			err := next(c)
+
+			// ============ 步骤 4: 如果有错误，先调用全局错误处理器 ============
+			// 这样可以确保日志中记录的 status code 是正确的错误状态码
+			if err != nil {
+				c.Error(err)
 			}
 
-			// ============ 步骤 7: 格式化并输出日志 ============
-			logStr := cfg.Formatter(param)
-			fmt.Fprint(cfg.Output, logStr)
+			emit(c.Response().Status, err)
 
 			// ============ 步骤 8: 返回原始错误 ============
 			// 即使已经通过 c.Error() 处理过，仍然返回原始错误
-			// 这样上层中间件可以继续处理，而全局错误处理器会检查 Committed 避免重复写入
+			// 这样上层中间件可以继续处理，c.Error 只在第一次调用时生效，全局错误处理器不会重复写入
 			return err
 		}
 	}
@@ -285,11 +545,3 @@ func getStatusEmoji(code int) string {
 		return "🔴"
 	}
 }
-
-func mustLoadLocation(name string) *time.Location {
-	loc, err := time.LoadLocation(name)
-	if err != nil {
-		return time.FixedZone("CST", 8*3600)
-	}
-	return loc
-}
@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lemonc7/zest"
+)
+
+// multiErr 动态类型是切片的错误，不能用 == 比较
+type multiErr []error
+
+func (m multiErr) Error() string { return errors.Join(m...).Error() }
+
+// headerCounter 统计 WriteHeader 实际被调用的次数
+type headerCounter struct {
+	*httptest.ResponseRecorder
+	headers int
+}
+
+func (w *headerCounter) WriteHeader(code int) {
+	w.headers++
+	w.ResponseRecorder.WriteHeader(code)
+}
+
+func TestLoggerErrorHandledOnce(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"plain error", errors.New("boom"), http.StatusInternalServerError},
+		{"http error", zest.NewHTTPError(http.StatusConflict, "conflict"), http.StatusConflict},
+		{"uncomparable error", multiErr{errors.New("a"), errors.New("b")}, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := zest.New()
+			calls := 0
+			z.ErrHandler = func(c *zest.Context, err error) {
+				calls++
+				zest.DefaultErrHandlerFunc(c, err)
+			}
+			z.Use(Logger(LoggerConfig{Output: io.Discard}))
+			// 全局中间件返回的错误先由 Logger 处理，再返回给引擎
+			z.Use(func(next zest.HandlerFunc) zest.HandlerFunc {
+				return func(c *zest.Context) error { return tt.err }
+			})
+			z.GET("/", func(c *zest.Context) error { return nil })
+
+			w := &headerCounter{ResponseRecorder: httptest.NewRecorder()}
+			z.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if calls != 1 {
+				t.Errorf("ErrHandler calls = %d, want 1", calls)
+			}
+			if w.headers != 1 {
+				t.Errorf("WriteHeader calls = %d, want 1", w.headers)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestLoggerErrorAfterCommit(t *testing.T) {
+	z := zest.New()
+	calls := 0
+	z.ErrHandler = func(c *zest.Context, err error) {
+		calls++
+		zest.DefaultErrHandlerFunc(c, err)
+	}
+	z.Use(Logger(LoggerConfig{Output: io.Discard}))
+	z.Use(func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			_ = c.String(http.StatusOK, "partial")
+			return errors.New("late")
+		}
+	})
+	z.GET("/", func(c *zest.Context) error { return nil })
+
+	rec := z.TestRequest(http.MethodGet, "/", nil)
+	if calls != 0 {
+		t.Errorf("ErrHandler calls = %d, want 0", calls)
+	}
+	if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+		t.Errorf("got %d %q, want 200 \"partial\"", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLoggerRecordsPanic(t *testing.T) {
+	tests := []struct {
+		name  string
+		order func(logger, recovery zest.MiddlewareFunc) []zest.MiddlewareFunc
+	}{
+		{"recovery outside logger", func(l, r zest.MiddlewareFunc) []zest.MiddlewareFunc { return []zest.MiddlewareFunc{r, l} }},
+		{"logger outside recovery", func(l, r zest.MiddlewareFunc) []zest.MiddlewareFunc { return []zest.MiddlewareFunc{l, r} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs []LogParam
+			logger := Logger(LoggerConfig{
+				Output: io.Discard,
+				Formatter: func(p LogParam) string {
+					logs = append(logs, p)
+					return ""
+				},
+			})
+			recovery := Recovery(RecoveryConfig{LogFunc: func(string, ...any) {}})
+
+			z := zest.New()
+			z.Use(tt.order(logger, recovery)...)
+			z.GET("/", func(c *zest.Context) error { panic("boom") })
+
+			rec := z.TestRequest(http.MethodGet, "/", nil)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want 500", rec.Code)
+			}
+			if len(logs) != 1 {
+				t.Fatalf("log lines = %d, want 1", len(logs))
+			}
+			if logs[0].Status != http.StatusInternalServerError {
+				t.Errorf("logged status = %d, want 500", logs[0].Status)
+			}
+			if logs[0].Error == nil || !strings.Contains(logs[0].Error.Error(), "panic: boom") {
+				t.Errorf("logged error = %v, want to contain %q", logs[0].Error, "panic: boom")
+			}
+		})
+	}
+}
+
+func TestLoggerTimeFormat(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		tz         *time.Location
+		timeFormat string
+		wantTZ     *time.Location
+		wantFormat string
+	}{
+		{"default is UTC", nil, "", time.UTC, "2006/01/02 15:04:05"},
+		{"custom location", shanghai, "", shanghai, "2006/01/02 15:04:05"},
+		{"custom format", nil, time.RFC3339, time.UTC, time.RFC3339},
+		{"custom format and location", shanghai, time.RFC3339, shanghai, time.RFC3339},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			z := zest.New()
+			z.Use(Logger(LoggerConfig{
+				Output:     &out,
+				TZ:         tt.tz,
+				TimeFormat: tt.timeFormat,
+			}))
+			z.GET("/", func(c *zest.Context) error { return c.NoContent(http.StatusOK) })
+
+			before := time.Now()
+			z.TestRequest(http.MethodGet, "/", nil)
+			after := time.Now()
+
+			// 请求在 before 和 after 之间完成，日志中的时间只可能是两者之一
+			line := out.String()
+			want := []string{
+				before.In(tt.wantTZ).Format(tt.wantFormat),
+				after.In(tt.wantTZ).Format(tt.wantFormat),
+			}
+			if !strings.Contains(line, " "+want[0]+" | ") && !strings.Contains(line, " "+want[1]+" | ") {
+				t.Errorf("log line = %q, want timestamp %q", line, want[0])
+			}
+		})
+	}
+}
+
+func TestLoggerRoute(t *testing.T) {
+	var param LogParam
+	z := zest.New()
+	z.Use(Logger(LoggerConfig{
+		Output: io.Discard,
+		Formatter: func(p LogParam) string {
+			param = p
+			return ""
+		},
+	}))
+	z.GET("/users/{id}", func(c *zest.Context) error { return c.NoContent(http.StatusOK) })
+
+	tests := []struct {
+		target    string
+		wantRoute string
+	}{
+		{"/users/42", "/users/{id}"},
+		{"/users/43?x=1", "/users/{id}"},
+		{"/missing", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			param = LogParam{}
+			z.TestRequest(http.MethodGet, tt.target, nil)
+			if param.Route != tt.wantRoute {
+				t.Errorf("Route = %q, want %q", param.Route, tt.wantRoute)
+			}
+		})
+	}
+}
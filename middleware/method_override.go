@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/lemonc7/zest"
+)
+
+// MethodOverrideConfig 方法覆盖中间件配置
+type MethodOverrideConfig struct {
+	// Skip 返回 true 时跳过
+	Skip func(c *zest.Context) bool
+	// Getter 从请求中获取要覆盖成的方法
+	// 可选，默认 MethodFromHeader(zest.HeaderXHTTPMethodOverride)
+	Getter func(c *zest.Context) string
+	// AllowedMethods 允许覆盖成的方法
+	// 可选，默认 PUT、PATCH、DELETE
+	AllowedMethods []string
+}
+
+// DefaultMethodOverrideConfig 默认配置
+var DefaultMethodOverrideConfig = MethodOverrideConfig{
+	Getter: MethodFromHeader(zest.HeaderXHTTPMethodOverride),
+	AllowedMethods: []string{
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+	},
+}
+
+// MethodOverride 返回 HTTP 方法覆盖中间件，让只能发送 GET/POST 的 HTML 表单也能调用 PUT/DELETE 路由
+// 只处理 POST 请求，且只能覆盖成 AllowedMethods 中的方法
+//
+// 必须通过 z.Use 注册为全局中间件：Go 的 ServeMux 按方法匹配路由，
+// 只有在进入 ServeMux 之前修改 c.Request.Method，请求才会被分发到 PUT/DELETE 路由。
+// 注册为分组或路由中间件时，路由已经按 POST 匹配完成，修改不会生效
+func MethodOverride(config ...MethodOverrideConfig) zest.MiddlewareFunc {
+	cfg := DefaultMethodOverrideConfig
+	if len(config) > 0 {
+		userCfg := config[0]
+		if userCfg.Skip != nil {
+			cfg.Skip = userCfg.Skip
+		}
+		if userCfg.Getter != nil {
+			cfg.Getter = userCfg.Getter
+		}
+		if len(userCfg.AllowedMethods) > 0 {
+			cfg.AllowedMethods = userCfg.AllowedMethods
+		}
+	}
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+
+			if c.Request.Method == http.MethodPost {
+				method := strings.ToUpper(strings.TrimSpace(cfg.Getter(c)))
+				if method != "" && slices.Contains(cfg.AllowedMethods, method) {
+					c.Request.Method = method
+					c.Method = method
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// MethodFromHeader 从请求头中获取覆盖方法
+func MethodFromHeader(header string) func(c *zest.Context) string {
+	return func(c *zest.Context) string {
+		return c.Request.Header.Get(header)
+	}
+}
+
+// MethodFromForm 从表单字段中获取覆盖方法，如 "_method"
+func MethodFromForm(param string) func(c *zest.Context) string {
+	return func(c *zest.Context) string {
+		return c.FormValue(param)
+	}
+}
+
+// MethodFromQuery 从查询参数中获取覆盖方法
+func MethodFromQuery(param string) func(c *zest.Context) string {
+	return func(c *zest.Context) string {
+		return c.Query(param)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lemonc7/zest"
+)
+
+func TestMethodOverride(t *testing.T) {
+	z := zest.New()
+	z.Use(MethodOverride())
+	// 每个方法注册独立的路由，响应体说明请求实际被分发到哪个路由
+	route := func(name string) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			return c.String(http.StatusOK, name+" "+c.Method+" "+c.Param("id"))
+		}
+	}
+	z.GET("/users/{id}", route("get"))
+	z.POST("/users/{id}", route("post"))
+	z.PUT("/users/{id}", route("put"))
+	z.DELETE("/users/{id}", route("delete"))
+
+	form := zest.New()
+	form.Use(MethodOverride(MethodOverrideConfig{Getter: MethodFromForm("_method")}))
+	form.PUT("/users/{id}", func(c *zest.Context) error { return c.String(http.StatusOK, "PUT "+c.FormValue("name")) })
+
+	tests := []struct {
+		name     string
+		z        *zest.Zest
+		method   string
+		override string
+		form     string
+		wantBody string
+	}{
+		{"header", z, http.MethodPost, "DELETE", "", "delete DELETE 1"},
+		{"lower case", z, http.MethodPost, " put ", "", "put PUT 1"},
+		{"not allowed", z, http.MethodPost, "GET", "", "post POST 1"},
+		{"only post", z, http.MethodGet, "DELETE", "", "get GET 1"},
+		{"no override", z, http.MethodPost, "", "", "post POST 1"},
+		{"form", form, http.MethodPost, "", "_method=PUT&name=zest", "PUT zest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/users/1", strings.NewReader(tt.form))
+			if tt.override != "" {
+				req.Header.Set(zest.HeaderXHTTPMethodOverride, tt.override)
+			}
+			if tt.form != "" {
+				req.Header.Set(zest.HeaderContentType, zest.MIMEApplicationForm)
+			}
+			rec := tt.z.Test(req)
+
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.wantBody {
+				t.Errorf("response = %d %q, want 200 %q", rec.Code, rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
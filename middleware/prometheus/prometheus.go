@@ -0,0 +1,159 @@
+// Package prometheus 提供记录请求指标的 Prometheus 中间件和暴露指标的处理器
+// 单独成包，只有用到它的程序才会引入 prometheus/client_golang
+package prometheus
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lemonc7/zest"
+	"github.com/lemonc7/zest/middleware"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Config Prometheus 指标中间件配置
+type Config struct {
+	// Skip 返回 true 时不记录指标
+	// 默认跳过 MetricsPath
+	Skip func(c *zest.Context) bool
+	// Namespace 指标名前缀
+	// 可选，默认 "zest"
+	Namespace string
+	// Subsystem 指标名的第二段前缀
+	Subsystem string
+	// Registerer 注册指标的位置
+	// 可选，默认 prometheus.DefaultRegisterer
+	Registerer prom.Registerer
+	// Buckets 请求耗时直方图的桶（秒）
+	// 可选，默认 prometheus.DefBuckets
+	Buckets []float64
+	// MetricsPath 暴露指标的路径，该路径自身不会被统计
+	// 可选，默认 "/metrics"
+	MetricsPath string
+}
+
+// DefaultConfig 默认配置
+var DefaultConfig = Config{
+	Namespace:   "zest",
+	Registerer:  prom.DefaultRegisterer,
+	Buckets:     prom.DefBuckets,
+	MetricsPath: "/metrics",
+}
+
+// New 返回记录请求指标的中间件
+// 包含请求总数、请求耗时直方图和处理中的请求数，route 标签使用路由模式（如 /users/{id}）而不是实际路径，避免标签基数爆炸
+// 需要通过 z.Use 注册为全局中间件，并配合 Handler 暴露指标：
+//
+//	z.Use(prometheus.New())
+//	z.GET("/metrics", prometheus.Handler(nil))
+func New(config ...Config) zest.MiddlewareFunc {
+	cfg := DefaultConfig
+	if len(config) > 0 {
+		userCfg := config[0]
+		if userCfg.Skip != nil {
+			cfg.Skip = userCfg.Skip
+		}
+		if userCfg.Namespace != "" {
+			cfg.Namespace = userCfg.Namespace
+		}
+		cfg.Subsystem = userCfg.Subsystem
+		if userCfg.Registerer != nil {
+			cfg.Registerer = userCfg.Registerer
+		}
+		if len(userCfg.Buckets) > 0 {
+			cfg.Buckets = userCfg.Buckets
+		}
+		if userCfg.MetricsPath != "" {
+			cfg.MetricsPath = userCfg.MetricsPath
+		}
+	}
+	if cfg.Skip == nil {
+		cfg.Skip = func(c *zest.Context) bool {
+			return c.Request.URL.Path == cfg.MetricsPath
+		}
+	}
+
+	labels := []string{"method", "route", "status"}
+	requests := registerCollector(cfg.Registerer, prom.NewCounterVec(prom.CounterOpts{
+		Namespace: cfg.Namespace,
+		Subsystem: cfg.Subsystem,
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests.",
+	}, labels))
+	duration := registerCollector(cfg.Registerer, prom.NewHistogramVec(prom.HistogramOpts{
+		Namespace: cfg.Namespace,
+		Subsystem: cfg.Subsystem,
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency in seconds.",
+		Buckets:   cfg.Buckets,
+	}, labels))
+	// 请求开始时还没有匹配路由，处理中的请求数只按方法区分
+	inFlight := registerCollector(cfg.Registerer, prom.NewGaugeVec(prom.GaugeOpts{
+		Namespace: cfg.Namespace,
+		Subsystem: cfg.Subsystem,
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being served.",
+	}, []string{"method"}))
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if cfg.Skip(c) {
+				return next(c)
+			}
+
+			method := c.Request.Method
+			gauge := inFlight.WithLabelValues(method)
+			gauge.Inc()
+			defer gauge.Dec()
+
+			start := time.Now()
+			err := next(c)
+
+			// 与 Logger 一样先交给错误处理器，确保记录的是最终状态码
+			if err != nil {
+				c.Error(err)
+			}
+			if middleware.ObserveSkipped(c) {
+				return err
+			}
+
+			status := strconv.Itoa(c.Response().Status)
+			route := c.RoutePattern()
+			requests.WithLabelValues(method, route, status).Inc()
+			duration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
+
+// Handler 返回暴露指标的处理器
+// gatherer 为 nil 时使用 prometheus.DefaultGatherer
+// 写入经过 c.Response()，Logger 等中间件能看到实际的状态码和大小
+func Handler(gatherer prom.Gatherer) zest.HandlerFunc {
+	if gatherer == nil {
+		gatherer = prom.DefaultGatherer
+	}
+	h := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+	return func(c *zest.Context) error {
+		h.ServeHTTP(c.Response(), c.Request)
+		return nil
+	}
+}
+
+// registerCollector 注册指标，已存在同名指标时复用已注册的那个
+// 这样多次调用 New() 不会 panic
+func registerCollector[T prom.Collector](r prom.Registerer, c T) T {
+	if err := r.Register(c); err != nil {
+		var are prom.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
@@ -0,0 +1,62 @@
+package prometheus
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lemonc7/zest"
+	"github.com/lemonc7/zest/middleware"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPrometheus(t *testing.T) {
+	reg := prom.NewRegistry()
+	z := zest.New()
+	var status int
+	var size int64
+	z.Use(func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			err := next(c)
+			status, size = c.Response().Status, c.Response().Size
+			return err
+		}
+	})
+	z.Use(New(Config{Registerer: reg}))
+	z.Use(middleware.HealthCheck(middleware.HealthConfig{}))
+	z.GET("/users/{id}", func(c *zest.Context) error { return c.String(http.StatusOK, "ok") })
+	z.GET("/metrics", Handler(reg))
+
+	z.TestRequest(http.MethodGet, "/users/1", nil)
+	z.TestRequest(http.MethodGet, "/users/2", nil)
+	z.TestRequest(http.MethodGet, "/healthz", nil)
+
+	tests := []struct {
+		name    string
+		want    string
+		present bool
+	}{
+		{"route label uses the pattern", `zest_requests_total{method="GET",route="/users/{id}",status="200"} 2`, true},
+		{"metrics path is skipped", `route="/metrics"`, false},
+		// 存活检查在路由匹配之前返回，记录下来的话 route 为空
+		{"liveness is skipped", `route=""`, false},
+	}
+
+	rec := z.TestRequest(http.MethodGet, "/metrics", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	// Handler 的写入经过 c.Response()
+	if status != http.StatusOK || size != int64(rec.Body.Len()) {
+		t.Errorf("Response() = %d/%d B, want 200/%d B", status, size, rec.Body.Len())
+	}
+
+	body := rec.Body.String()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Contains(body, tt.want); got != tt.present {
+				t.Errorf("metrics contain %q = %v, want %v", tt.want, got, tt.present)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lemonc7/zest"
+)
+
+// RateLimiterConfig 限流中间件配置
+type RateLimiterConfig struct {
+	// Skip 返回 true 时跳过限流
+	Skip func(c *zest.Context) bool
+	// Rate 每秒补充的令牌数，使用默认的内存存储时必填
+	Rate float64
+	// Burst 令牌桶容量，即允许的瞬时并发数
+	// 可选，默认为 Rate 向上取整（至少为 1）
+	Burst int
+	// KeyFunc 返回限流的 key，返回空字符串时退回到 c.RealIP()
+	// 可选，默认使用 c.RealIP()；按登录用户限流可使用 KeyByContextValue("sub")
+	KeyFunc func(c *zest.Context) string
+	// Store 令牌桶存储，可替换为 Redis 等实现
+	// 可选，默认使用 NewRateLimiterMemoryStore(Rate, Burst, 0)
+	Store RateLimiterStore
+}
+
+// RateLimiterStore 限流存储接口
+type RateLimiterStore interface {
+	// Allow 尝试为 key 消耗一个令牌
+	Allow(key string) (RateLimitResult, error)
+}
+
+// RateLimitResult 一次限流判断的结果
+type RateLimitResult struct {
+	// Allowed 是否放行
+	Allowed bool
+	// Limit 令牌桶容量
+	Limit int
+	// Remaining 剩余令牌数
+	Remaining int
+	// RetryAfter 被拒绝时，距离下一个令牌可用的时间
+	RetryAfter time.Duration
+}
+
+// RateLimiter 返回令牌桶限流中间件
+// 请求被拒绝时返回 429，并设置 X-RateLimit-Limit、X-RateLimit-Remaining 和 Retry-After 响应头
+func RateLimiter(config RateLimiterConfig) zest.MiddlewareFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(c *zest.Context) string {
+			return c.RealIP()
+		}
+	}
+	if config.Store == nil {
+		if config.Rate <= 0 {
+			panic("zest: rate limiter requires a positive Rate")
+		}
+		config.Store = NewRateLimiterMemoryStore(config.Rate, config.Burst, 0)
+	}
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if config.Skip != nil && config.Skip(c) {
+				return next(c)
+			}
+
+			key := config.KeyFunc(c)
+			if key == "" {
+				key = c.RealIP()
+			}
+			res, err := config.Store.Allow(key)
+			if err != nil {
+				return zest.NewHTTPError(http.StatusInternalServerError).Wrap(err)
+			}
+
+			c.SetHeader("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+			c.SetHeader("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			if !res.Allowed {
+				retryAfter := int(math.Ceil(res.RetryAfter.Seconds()))
+				return zest.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded").
+					WithHeader(zest.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// KeyByContextValue 返回按 c.Get(name) 限流的 KeyFunc，用于按 JWT 等中间件存入的用户 ID 限流
+// 返回的 key 带有 name 前缀，不会与 IP 冲突；值不存在时返回空字符串，由 RateLimiter 退回到按 IP 限流
+func KeyByContextValue(name string) func(c *zest.Context) string {
+	return func(c *zest.Context) string {
+		v := c.Get(name)
+		if v == nil {
+			return ""
+		}
+		s := fmt.Sprint(v)
+		if s == "" {
+			return ""
+		}
+		return name + ":" + s
+	}
+}
+
+// RateLimiterMemoryStore 基于内存的令牌桶存储，并发安全
+type RateLimiterMemoryStore struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       int
+	expiresIn   time.Duration
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// NewRateLimiterMemoryStore 创建内存令牌桶存储
+// expiresIn 为空闲 key 的过期时间，默认 3 分钟；过期的 key 会在后续请求中被惰性清理，避免一次性 IP 占用内存
+func NewRateLimiterMemoryStore(rate float64, burst int, expiresIn time.Duration) *RateLimiterMemoryStore {
+	if burst <= 0 {
+		burst = max(int(math.Ceil(rate)), 1)
+	}
+	if expiresIn <= 0 {
+		expiresIn = 3 * time.Minute
+	}
+	return &RateLimiterMemoryStore{
+		rate:      rate,
+		burst:     burst,
+		expiresIn: expiresIn,
+		buckets:   make(map[string]*bucket),
+	}
+}
+
+// Allow 实现 RateLimiterStore
+func (s *RateLimiterMemoryStore) Allow(key string) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastCleanup) > s.expiresIn {
+		s.cleanup(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(s.burst), lastSeen: now}
+		s.buckets[key] = b
+	}
+
+	// 按经过的时间补充令牌
+	b.tokens = math.Min(float64(s.burst), b.tokens+now.Sub(b.lastSeen).Seconds()*s.rate)
+	b.lastSeen = now
+
+	res := RateLimitResult{Limit: s.burst}
+	if b.tokens < 1 {
+		res.RetryAfter = time.Duration((1 - b.tokens) / s.rate * float64(time.Second))
+		return res, nil
+	}
+
+	b.tokens--
+	res.Allowed = true
+	res.Remaining = int(b.tokens)
+	return res, nil
+}
+
+// cleanup 删除超过 expiresIn 没有访问的 key
+func (s *RateLimiterMemoryStore) cleanup(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.lastSeen) > s.expiresIn {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastCleanup = now
+}
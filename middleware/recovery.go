@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
+	"html"
 	"log"
 	"net/http"
+	"os"
 	"runtime"
 	"strings"
 
@@ -18,16 +21,33 @@ type RecoveryConfig struct {
 	// LogFunc 自定义日志打印函数
 	// 默认为 log.Printf
 	LogFunc func(format string, v ...any)
+	// DebugStack 在 500 响应中返回 panic 的值和堆栈，按 Accept 返回 JSON、HTML 或纯文本
+	// 只应在开发环境开启；关闭时只返回通用的 500 信息，不泄露内部细节
+	// 默认 false
+	DebugStack bool
+	// StackFrames 最多记录的堆栈帧数，调用层级很深时可以调大
+	// 默认 32
+	StackFrames int
+	// FuncNames 堆栈中每一帧同时记录函数名，默认只记录文件和行号
+	FuncNames bool
+	// SourceLines 每一帧附带前后各多少行源码，便于本地调试
+	// 只在 DebugStack 开启时生效，生产环境不会读取源文件；默认 0，不附带源码
+	SourceLines int
+	// OnPanic 捕获到 panic 后调用，可用于上报 Sentry 等告警系统，网络连接中断时不会调用
+	// 在生成 500 响应之前执行；它自身的 panic 会被吞掉，不会导致服务崩溃
+	OnPanic func(c *zest.Context, recovered any, stack []byte)
 }
 
 // DefaultRecoveryConfig 默认配置
 var DefaultRecoveryConfig = RecoveryConfig{
-	Skip:    3,
-	LogFunc: log.Printf,
+	Skip:        3,
+	LogFunc:     log.Printf,
+	StackFrames: 32,
 }
 
 // Recovery 返回一个中间件，用于捕获 panic 并恢复，防止服务器崩溃
 // 如果发生 panic，会记录堆栈信息，并返回 500 错误以便后续中间件（如 Logger）和全局错误处理器处理
+// panic(http.ErrAbortHandler) 会被重新抛出，交给 http.Server 静默中断连接
 func Recovery(config ...RecoveryConfig) zest.MiddlewareFunc {
 	cfg := DefaultRecoveryConfig
 	if len(config) > 0 {
@@ -38,6 +58,13 @@ func Recovery(config ...RecoveryConfig) zest.MiddlewareFunc {
 		if userCfg.LogFunc != nil {
 			cfg.LogFunc = userCfg.LogFunc
 		}
+		if userCfg.StackFrames > 0 {
+			cfg.StackFrames = userCfg.StackFrames
+		}
+		cfg.DebugStack = userCfg.DebugStack
+		cfg.FuncNames = userCfg.FuncNames
+		cfg.SourceLines = userCfg.SourceLines
+		cfg.OnPanic = userCfg.OnPanic
 	}
 
 	return func(next zest.HandlerFunc) zest.HandlerFunc {
@@ -45,6 +72,12 @@ func Recovery(config ...RecoveryConfig) zest.MiddlewareFunc {
 			// ============ 使用 defer + recover 捕获 panic ============
 			defer func() {
 				if r := recover(); r != nil {
+					// http.ErrAbortHandler 是标准库约定的中止信号，不是程序崩溃
+					// 原样抛出，由 http.Server 中断连接且不打印堆栈
+					if e, ok := r.(error); ok && errors.Is(e, http.ErrAbortHandler) {
+						panic(r)
+					}
+
 					// ========== 步骤 1: 检查是否是网络连接中断 ==========
 					var brokenPipe bool
 					if ne, ok := r.(netError); ok {
@@ -58,10 +91,17 @@ func Recovery(config ...RecoveryConfig) zest.MiddlewareFunc {
 					// ========== 步骤 2: 获取堆栈信息 ==========
 					// 如果不是 Broken Pipe，或者是 Broken Pipe 但我们也想看一点信息（通常 BrokenPipe 不需要看堆栈）
 					// 这里保持逻辑：BrokenPipe 不打印堆栈
+					var stack string
 					if !brokenPipe {
-						trace := trace(cfg.Skip)
+						if z := c.Zest(); z != nil {
+							z.RecordPanic()
+						}
+						stack = trace(cfg)
 						// 使用配置的 LogFunc 打印到 stderr 或文件
-						cfg.LogFunc("[Recovery] panic recovered:\n%v\n%s", r, trace)
+						cfg.LogFunc("[Recovery] panic recovered:\n%v\n%s", r, stack)
+						if cfg.OnPanic != nil {
+							callOnPanic(cfg.OnPanic, c, r, []byte(stack))
+						}
 					}
 
 					// ========== 步骤 3: 构造错误返回 ==========
@@ -71,14 +111,26 @@ func Recovery(config ...RecoveryConfig) zest.MiddlewareFunc {
 						return
 					}
 
+					// 如果响应已经部分写入了（c.Response().Committed），不能再覆盖成 500
+					// 只返回普通 error 供 Logger 记录，客户端只能接收截断的数据
+					if c.Response().Committed {
+						err = fmt.Errorf("panic after response committed: %v", r)
+						return
+					}
+
+					// 开发模式直接写出带堆栈的响应，panic 已经通过 LogFunc 记录
+					// 响应已经提交，返回 nil，避免错误处理器再为同一个 panic 记录一次
+					if cfg.DebugStack {
+						writeDebugStack(c, r, stack)
+						err = nil
+						return
+					}
+
 					// 将 panic 转换为 error 返回
-					// 这样 Logger 中间件可以记录这个 Error
-					// Zest 核心会捕获这个 Error 并调用 ErrHandler 返回 500 JSON
+					// 这样 Logger 中间件可以记录这个 Error（panic 的值在内部错误中）
+					// Zest 核心会捕获这个 Error 并调用 ErrHandler 返回通用的 500
 					// err隐式返回
-					err = zest.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("%v", r))
-
-					// 如果响应头还没写入，Zest ErrHandler 会负责写入
-					// 如果响应已经部分写入了（c.Response().Committed），那也没办法了，只能让客户端接收截断的数据
+					err = zest.NewHTTPError(http.StatusInternalServerError).Wrap(fmt.Errorf("panic: %v", r))
 				}
 			}()
 
@@ -88,23 +140,75 @@ func Recovery(config ...RecoveryConfig) zest.MiddlewareFunc {
 	}
 }
 
+// callOnPanic 调用 OnPanic，吞掉其中的 panic
+func callOnPanic(fn func(c *zest.Context, recovered any, stack []byte), c *zest.Context, recovered any, stack []byte) {
+	defer func() {
+		_ = recover()
+	}()
+	fn(c, recovered, stack)
+}
+
+// writeDebugStack 按 Accept 输出 panic 的值和堆栈
+func writeDebugStack(c *zest.Context, recovered any, stack string) {
+	msg := fmt.Sprint(recovered)
+	switch c.Accepts(zest.MIMEApplicationJSON, zest.MIMETextHTML, zest.MIMETextPlain) {
+	case zest.MIMETextHTML:
+		_ = c.HTML(http.StatusInternalServerError, "<!DOCTYPE html>\n<html>\n<head><meta charset=\"UTF-8\"><title>500 panic</title></head>\n<body><h1>panic: "+
+			html.EscapeString(msg)+"</h1><pre>"+html.EscapeString(stack)+"</pre></body>\n</html>\n")
+	case zest.MIMETextPlain:
+		_ = c.String(http.StatusInternalServerError, "panic: "+msg+"\n\n"+stack)
+	default:
+		frames := strings.Split(strings.TrimSpace(stack), "\n")
+		for i := range frames {
+			frames[i] = strings.TrimSpace(frames[i])
+		}
+		_ = c.JSON(http.StatusInternalServerError, zest.Map{"error": "panic: " + msg, "stack": frames})
+	}
+}
+
 // netError 网络错误接口
 type netError interface {
 	Error() string
 }
 
 // trace 获取堆栈跟踪信息
-func trace(skip int) string {
-	var pcs [32]uintptr
-	n := runtime.Callers(skip, pcs[:])
+// 每一帧输出 file:line，开启 FuncNames 时先输出函数名；开启 DebugStack 且 SourceLines 大于 0 时附带源码
+func trace(cfg RecoveryConfig) string {
+	pcs := make([]uintptr, cfg.StackFrames)
+	n := runtime.Callers(cfg.Skip, pcs)
+	withSource := cfg.DebugStack && cfg.SourceLines > 0
 	var b strings.Builder
 	frames := runtime.CallersFrames(pcs[:n])
 	for {
 		frame, more := frames.Next()
+		if cfg.FuncNames {
+			fmt.Fprintf(&b, "\t%s\n\t", frame.Function)
+		}
 		fmt.Fprintf(&b, "\t%s:%d\n", frame.File, frame.Line)
+		if withSource {
+			writeSource(&b, frame.File, frame.Line, cfg.SourceLines)
+		}
 		if !more {
 			break
 		}
 	}
 	return b.String()
 }
+
+// writeSource 写入 file 中 line 前后各 around 行源码，当前行以 > 标出；读取失败时什么也不写
+func writeSource(b *strings.Builder, file string, line, around int) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(string(data), "\n")
+	start := max(line-around, 1)
+	end := min(line+around, len(lines))
+	for i := start; i <= end; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(b, "\t\t%s %4d | %s\n", marker, i, lines[i-1])
+	}
+}
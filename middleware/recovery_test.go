@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lemonc7/zest"
+)
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name        string
+		debugStack  bool
+		accept      string
+		wantType    string
+		wantError   string
+		wantStack   bool
+		wantContain string
+	}{
+		{"production json", false, "", zest.MIMEApplicationJSON, "Internal Server Error", false, ""},
+		{"debug json", true, "", zest.MIMEApplicationJSON, "panic: boom", true, ""},
+		{"debug html", true, zest.MIMETextHTML, zest.MIMETextHTMLCharsetUTF8, "", false, "<h1>panic: boom</h1>"},
+		{"debug text", true, zest.MIMETextPlain, zest.MIMETextPlainCharsetUTF8, "", false, "panic: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			z := zest.New()
+			z.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+			z.Use(Recovery(RecoveryConfig{
+				DebugStack: tt.debugStack,
+				LogFunc:    func(string, ...any) {},
+			}))
+			z.GET("/", func(c *zest.Context) error {
+				panic("boom")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set(zest.HeaderAccept, tt.accept)
+			}
+			rec := z.Test(req)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want 500", rec.Code)
+			}
+			if got := rec.Header().Get(zest.HeaderContentType); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if tt.wantContain != "" && !strings.Contains(rec.Body.String(), tt.wantContain) {
+				t.Errorf("body = %q, want to contain %q", rec.Body.String(), tt.wantContain)
+			}
+			if tt.wantError != "" {
+				var body struct {
+					Error string   `json:"error"`
+					Stack []string `json:"stack"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatalf("invalid JSON body %q: %v", rec.Body.String(), err)
+				}
+				if body.Error != tt.wantError {
+					t.Errorf("error = %q, want %q", body.Error, tt.wantError)
+				}
+				if got := len(body.Stack) > 0; got != tt.wantStack {
+					t.Errorf("has stack = %v, want %v", got, tt.wantStack)
+				}
+			}
+			// 响应只写一次，不会因为同一个 panic 再记录 "error after response committed"
+			if logs.Len() > 0 {
+				t.Errorf("unexpected log output: %s", logs.String())
+			}
+		})
+	}
+}
+
+func TestRecoveryResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    zest.HandlerFunc
+		wantStatus int
+		wantBody   string
+		wantLogged bool
+	}{
+		{
+			name:       "panic before write",
+			handler:    func(c *zest.Context) error { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"Internal Server Error"}`,
+			wantLogged: true,
+		},
+		{
+			name: "panic after partial write",
+			handler: func(c *zest.Context) error {
+				_ = c.String(http.StatusOK, "partial")
+				panic("boom")
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "partial",
+			wantLogged: true,
+		},
+		{
+			name:       "broken pipe",
+			handler:    func(c *zest.Context) error { panic(errors.New("write tcp: broken pipe")) },
+			wantStatus: http.StatusOK,
+			wantBody:   "",
+			wantLogged: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logged := false
+			z := zest.New()
+			z.Logger = slog.New(slog.DiscardHandler)
+			z.Use(Recovery(RecoveryConfig{
+				LogFunc: func(string, ...any) { logged = true },
+			}))
+			z.GET("/", tt.handler)
+
+			rec := z.TestRequest(http.MethodGet, "/", nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if logged != tt.wantLogged {
+				t.Errorf("logged = %v, want %v", logged, tt.wantLogged)
+			}
+		})
+	}
+}
+
+func TestRecoveryAbortHandler(t *testing.T) {
+	z := zest.New()
+	z.Use(Recovery(RecoveryConfig{LogFunc: func(string, ...any) {}}))
+	z.GET("/", func(c *zest.Context) error { panic(http.ErrAbortHandler) })
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+		}
+	}()
+	z.TestRequest(http.MethodGet, "/", nil)
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/lemonc7/zest"
+)
+
+// RedirectConfig 重定向中间件配置
+type RedirectConfig struct {
+	// Skip 返回 true 时跳过
+	Skip func(c *zest.Context) bool
+	// Code 重定向状态码
+	// 默认 301；使用 308 可以保证 POST 等非幂等请求重定向后方法和请求体不变
+	Code int
+	// AddSlash 只对 TrailingSlashRedirect 生效
+	// true 表示补上末尾的 /，false（默认）表示去掉末尾的 /
+	AddSlash bool
+}
+
+// DefaultRedirectConfig 默认配置
+var DefaultRedirectConfig = RedirectConfig{
+	Code: http.StatusMovedPermanently,
+}
+
+// HTTPSRedirect 将 HTTP 请求重定向到 https://
+// 通过 c.Scheme() 和 c.Host() 判断协议和主机，部署在代理后面时需要通过 Zest.TrustedProxies 信任代理的 X-Forwarded-* 请求头
+func HTTPSRedirect(config ...RedirectConfig) zest.MiddlewareFunc {
+	return redirect(func(c *zest.Context, scheme, host, path string) (string, bool) {
+		if scheme == "https" {
+			return "", false
+		}
+		return "https://" + host + path, true
+	}, config...)
+}
+
+// WWWRedirect 将 example.com 重定向到 www.example.com
+func WWWRedirect(config ...RedirectConfig) zest.MiddlewareFunc {
+	return redirect(func(c *zest.Context, scheme, host, path string) (string, bool) {
+		if strings.HasPrefix(host, "www.") {
+			return "", false
+		}
+		return scheme + "://www." + host + path, true
+	}, config...)
+}
+
+// NonWWWRedirect 将 www.example.com 重定向到 example.com
+func NonWWWRedirect(config ...RedirectConfig) zest.MiddlewareFunc {
+	return redirect(func(c *zest.Context, scheme, host, path string) (string, bool) {
+		if !strings.HasPrefix(host, "www.") {
+			return "", false
+		}
+		return scheme + "://" + host[len("www."):] + path, true
+	}, config...)
+}
+
+// TrailingSlashRedirect 统一路径末尾的 /，由 RedirectConfig.AddSlash 决定补上还是去掉
+// 需要通过 z.Use 注册为全局中间件，在路由匹配之前生效
+func TrailingSlashRedirect(config ...RedirectConfig) zest.MiddlewareFunc {
+	addSlash := len(config) > 0 && config[0].AddSlash
+	return redirect(func(c *zest.Context, scheme, host, path string) (string, bool) {
+		p := c.Request.URL.EscapedPath()
+		if p == "/" || p == "" {
+			return "", false
+		}
+		hasSlash := strings.HasSuffix(p, "/")
+		if addSlash == hasSlash {
+			return "", false
+		}
+		if addSlash {
+			p += "/"
+		} else {
+			p = strings.TrimRight(p, "/")
+		}
+		// 开头连续的 / 或 \ 合并成一个，否则 "//evil.com" 会被浏览器当作协议相对地址跳转到其他主机
+		p = "/" + strings.TrimLeft(p, "/\\")
+		if c.Request.URL.RawQuery != "" {
+			p += "?" + c.Request.URL.RawQuery
+		}
+		// 只改路径时使用相对地址，避免在代理后拼错协议和主机
+		return p, true
+	}, config...)
+}
+
+// redirect 重定向中间件的通用实现
+// target 返回重定向地址以及是否需要重定向；path 包含查询参数
+func redirect(target func(c *zest.Context, scheme, host, path string) (string, bool), config ...RedirectConfig) zest.MiddlewareFunc {
+	cfg := DefaultRedirectConfig
+	if len(config) > 0 {
+		userCfg := config[0]
+		if userCfg.Skip != nil {
+			cfg.Skip = userCfg.Skip
+		}
+		if userCfg.Code != 0 {
+			cfg.Code = userCfg.Code
+		}
+	}
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+
+			url, ok := target(c, c.Scheme(), c.Host(), c.Request.URL.RequestURI())
+			if !ok {
+				return next(c)
+			}
+			return c.Redirect(cfg.Code, url)
+		}
+	}
+}
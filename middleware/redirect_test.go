@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lemonc7/zest"
+)
+
+func TestRedirect(t *testing.T) {
+	tests := []struct {
+		name         string
+		mw           zest.MiddlewareFunc
+		target       string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"https", HTTPSRedirect(), "http://example.com/a?x=1", http.StatusMovedPermanently, "https://example.com/a?x=1"},
+		{"https custom code", HTTPSRedirect(RedirectConfig{Code: http.StatusPermanentRedirect}), "http://example.com/a", http.StatusPermanentRedirect, "https://example.com/a"},
+		{"https already", HTTPSRedirect(), "https://example.com/a", http.StatusOK, ""},
+		{"www", WWWRedirect(), "http://example.com/a?x=1", http.StatusMovedPermanently, "http://www.example.com/a?x=1"},
+		{"www custom code", WWWRedirect(RedirectConfig{Code: http.StatusFound}), "http://example.com/a", http.StatusFound, "http://www.example.com/a"},
+		{"www already", WWWRedirect(), "http://www.example.com/a", http.StatusOK, ""},
+		{"non-www", NonWWWRedirect(), "http://www.example.com/a?x=1", http.StatusMovedPermanently, "http://example.com/a?x=1"},
+		{"non-www custom code", NonWWWRedirect(RedirectConfig{Code: http.StatusTemporaryRedirect}), "http://www.example.com/a", http.StatusTemporaryRedirect, "http://example.com/a"},
+		{"non-www already", NonWWWRedirect(), "http://example.com/a", http.StatusOK, ""},
+		{"strip slash", TrailingSlashRedirect(), "/a/?x=1", http.StatusMovedPermanently, "/a?x=1"},
+		{"strip slash custom code", TrailingSlashRedirect(RedirectConfig{Code: http.StatusPermanentRedirect}), "/a/", http.StatusPermanentRedirect, "/a"},
+		{"add slash", TrailingSlashRedirect(RedirectConfig{AddSlash: true}), "/a?x=1", http.StatusMovedPermanently, "/a/?x=1"},
+		{"root untouched", TrailingSlashRedirect(), "/", http.StatusOK, ""},
+		{"skip", HTTPSRedirect(RedirectConfig{Skip: func(*zest.Context) bool { return true }}), "http://example.com/a", http.StatusOK, ""},
+		// 开头的多个 / 不能生成协议相对地址
+		{"strip slash protocol-relative", TrailingSlashRedirect(), "//evil.com/", http.StatusMovedPermanently, "/evil.com"},
+		{"strip slash many leading slashes", TrailingSlashRedirect(), "///evil.com/?x=1", http.StatusMovedPermanently, "/evil.com?x=1"},
+		{"add slash protocol-relative", TrailingSlashRedirect(RedirectConfig{AddSlash: true}), "//evil.com", http.StatusMovedPermanently, "/evil.com/"},
+		{"add slash backslash", TrailingSlashRedirect(RedirectConfig{AddSlash: true}), "/%5Cevil.com", http.StatusMovedPermanently, "/%5Cevil.com/"},
+		{"only slashes", TrailingSlashRedirect(), "///", http.StatusMovedPermanently, "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := zest.New()
+			z.Use(tt.mw)
+			// 全局中间件先于路由匹配执行，兜底路由返回 200 表示没有重定向
+			z.NotFound(func(c *zest.Context) error { return c.String(http.StatusOK, "ok") })
+
+			rec := z.Test(httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get(zest.HeaderLocation); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
@@ -16,6 +16,17 @@ type RequestIDConfig struct {
 	Generator func() string
 }
 
+// ctxKey 中间件写入 context.Context 的 key 类型，避免与其他包冲突
+type ctxKey int
+
+const requestIDKey ctxKey = iota
+
+// RequestIDFromContext 从 context.Context 中读取 RequestID 中间件生成的请求 ID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	rid, ok := ctx.Value(requestIDKey).(string)
+	return rid, ok
+}
+
 // DefaultRequestIDConfig 默认配置
 var DefaultRequestIDConfig = RequestIDConfig{
 	Header: "X-Request-ID",
@@ -27,6 +38,7 @@ var DefaultRequestIDConfig = RequestIDConfig{
 }
 
 // RequestID 返回一个生成唯一请求 ID 的中间件
+// 同时为 c.Logger() 加上 request_id 属性，处理器中通过 c.Logger() 记录的日志会带上请求 ID
 func RequestID(config ...RequestIDConfig) zest.MiddlewareFunc {
 	cfg := DefaultRequestIDConfig
 	if len(config) > 0 {
@@ -49,11 +61,12 @@ func RequestID(config ...RequestIDConfig) zest.MiddlewareFunc {
 
 			// 2. 注入到响应头与上下文，方便跨函数传递
 			c.SetHeader(cfg.Header, rid)
-			ctx := context.WithValue(c.Context(), "requestID", rid)
+			ctx := context.WithValue(c.Context(), requestIDKey, rid)
 			c.Request = c.Request.WithContext(ctx)
 
 			// 3. 注入到 Context 存储中，方便后续业务逻辑使用
 			c.Set("requestID", rid)
+			c.SetLogger(c.Logger().With("request_id", rid))
 
 			return next(c)
 		}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/lemonc7/zest"
+)
+
+// SecureConfig 安全响应头中间件配置
+// 字符串字段为空时不设置对应的响应头
+type SecureConfig struct {
+	// Skip 返回 true 时跳过
+	Skip func(c *zest.Context) bool
+	// XSSProtection X-XSS-Protection 响应头
+	XSSProtection string
+	// ContentTypeNosniff X-Content-Type-Options 响应头
+	ContentTypeNosniff string
+	// XFrameOptions X-Frame-Options 响应头，可选 DENY、SAMEORIGIN
+	XFrameOptions string
+	// ContentSecurityPolicy Content-Security-Policy 响应头
+	ContentSecurityPolicy string
+	// CSPReportOnly 为 true 时使用 Content-Security-Policy-Report-Only，只上报不拦截
+	CSPReportOnly bool
+	// ReferrerPolicy Referrer-Policy 响应头
+	ReferrerPolicy string
+	// HSTSMaxAge Strict-Transport-Security 的 max-age（秒），为 0 时不设置
+	// 只在 HTTPS 请求中发送
+	HSTSMaxAge int
+	// HSTSIncludeSubdomains 在 HSTS 中加入 includeSubDomains
+	HSTSIncludeSubdomains bool
+	// HSTSPreload 在 HSTS 中加入 preload
+	HSTSPreload bool
+}
+
+// DefaultSecureConfig 默认配置
+// 不包含 CSP 和 HSTS，避免影响尚未准备好的应用
+var DefaultSecureConfig = SecureConfig{
+	XSSProtection:      "1; mode=block",
+	ContentTypeNosniff: "nosniff",
+	XFrameOptions:      "SAMEORIGIN",
+	ReferrerPolicy:     "strict-origin-when-cross-origin",
+}
+
+// Secure 返回设置安全响应头的中间件
+// 传入配置时会完整替换默认配置，以便将某个响应头置空来禁用它
+// 只想修改个别字段时，建议基于 DefaultSecureConfig 修改后传入
+func Secure(config ...SecureConfig) zest.MiddlewareFunc {
+	cfg := DefaultSecureConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	cspHeader := zest.HeaderContentSecurityPolicy
+	if cfg.CSPReportOnly {
+		cspHeader = zest.HeaderContentSecurityPolicyReportOnly
+	}
+
+	return func(next zest.HandlerFunc) zest.HandlerFunc {
+		return func(c *zest.Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+
+			if cfg.XSSProtection != "" {
+				c.SetHeader(zest.HeaderXXSSProtection, cfg.XSSProtection)
+			}
+			if cfg.ContentTypeNosniff != "" {
+				c.SetHeader(zest.HeaderXContentTypeOptions, cfg.ContentTypeNosniff)
+			}
+			if cfg.XFrameOptions != "" {
+				c.SetHeader(zest.HeaderXFrameOptions, cfg.XFrameOptions)
+			}
+			if cfg.ContentSecurityPolicy != "" {
+				c.SetHeader(cspHeader, cfg.ContentSecurityPolicy)
+			}
+			if cfg.ReferrerPolicy != "" {
+				c.SetHeader(zest.HeaderReferrerPolicy, cfg.ReferrerPolicy)
+			}
+			// HSTS 在 HTTP 响应中无效，规范要求浏览器忽略
+			if hsts != "" && c.IsTLS() {
+				c.SetHeader(zest.HeaderStrictTransportSecurity, hsts)
+			}
+
+			return next(c)
+		}
+	}
+}
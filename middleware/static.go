@@ -4,10 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lemonc7/zest"
 )
@@ -26,9 +33,43 @@ type StaticConfig struct {
 	// Browse 是否允许目录浏览
 	// 可选，默认值 false
 	Browse bool
+	// ShowHidden 目录浏览时是否列出以 . 开头的文件（如 .git、.env）
+	// 可选，默认值 false，即隐藏
+	ShowHidden bool
+	// AllowDotfiles 是否允许访问以 . 开头的路径（如 /.env、/.git/config）
+	// 默认 false，即拒绝访问，与 Zest.DenyDotfiles 的默认行为一致；被拒绝的请求交给后续路由处理
+	// 可选，默认值 false
+	AllowDotfiles bool
 	// Filesystem 提供对静态内容的访问
 	// 可选，默认为 http.Dir(config.Root)
+	// 使用 //go:embed 嵌入的资源时传入 http.FS(embedFS)，Root 为 embed 中的子目录（如 "dist"）
+	// 嵌入的文件没有修改时间，会根据文件大小生成 ETag 代替 Last-Modified
 	Filesystem http.FileSystem
+	// Precompressed 优先发送构建时预压缩好的同名 .br/.gz 文件
+	// 客户端接受对应编码且存在 "file.ext.br" 或 "file.ext.gz" 时，以原文件的 Content-Type 发送压缩文件，br 优先
+	// 没有预压缩文件或客户端不接受时回退到原文件；开启后响应都会带上 Vary: Accept-Encoding
+	// 可选，默认值 false
+	Precompressed bool
+	// MaxAge 文件响应的 Cache-Control max-age，设置后发送 "Cache-Control: public, max-age=..."
+	// 目录浏览生成的页面不受影响
+	// 可选，默认不设置 Cache-Control
+	MaxAge time.Duration
+	// Immutable 在 MaxAge 的基础上追加 immutable，适合文件名带哈希的资源
+	// 可选，默认值 false
+	Immutable bool
+	// CacheControlByExt 按扩展名覆盖 Cache-Control 的值，key 为带点的小写扩展名
+	// 例如 {".html": "no-cache"}，让 HTML 每次协商而 JS/CSS 使用 MaxAge 长期缓存
+	// 可选
+	CacheControlByExt map[string]string
+}
+
+// precompressedEncodings 预压缩文件的编码和扩展名，按优先级排列
+var precompressedEncodings = []struct {
+	encoding string
+	ext      string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
 }
 
 const dirListHtml = `
@@ -47,7 +88,7 @@ const dirListHtml = `
     li a { display: block; padding: 15px; text-decoration: none; color: #3b82f6; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
     .dir { color: #ec4899; font-weight: 500; }
     .file { color: #6366f1; }
-    .size { font-size: 12px; color: #94a3b8; margin-left: 8px; }
+    .size, .time { font-size: 12px; color: #94a3b8; margin-left: 15px; }
   </style>
 </head>
 <body>
@@ -56,11 +97,12 @@ const dirListHtml = `
     {{ range .Files }}
     <li>
       {{ if .IsDir }}
-        <a class="dir" href="{{ .Name }}/">{{ .Name }}/</a>
+        <a class="dir" href="{{ .Href }}/">{{ .Name }}/</a>
       {{ else }}
-        <a class="file" href="{{ .Name }}">{{ .Name }}</a>
+        <a class="file" href="{{ .Href }}">{{ .Name }}</a>
         <span class="size">{{ .Size }}</span>
       {{ end }}
+      <span class="time">{{ .ModTime }}</span>
     </li>
     {{ end }}
   </ul>
@@ -82,6 +124,15 @@ func Static(config StaticConfig) zest.MiddlewareFunc {
 		config.Root = "."
 	}
 
+	// 默认的 Cache-Control，由 MaxAge 和 Immutable 生成
+	cacheControl := ""
+	if config.MaxAge > 0 {
+		cacheControl = "public, max-age=" + strconv.FormatInt(int64(config.MaxAge.Seconds()), 10)
+		if config.Immutable {
+			cacheControl += ", immutable"
+		}
+	}
+
 	// 预加载模板
 	t, tErr := template.New("dirlist").Parse(dirListHtml)
 	if tErr != nil {
@@ -101,6 +152,14 @@ func Static(config StaticConfig) zest.MiddlewareFunc {
 				return next(c)
 			}
 
+			// 显式拒绝 .. 路径穿越，不单纯依赖 path.Clean
+			if hasDotDot(p) {
+				return zest.NewHTTPError(http.StatusBadRequest, "invalid path")
+			}
+			if !config.AllowDotfiles && hasDotfile(p) {
+				return next(c)
+			}
+
 			// 使用 path.Clean 确保 URL 路径安全
 			name := path.Join(config.Root, path.Clean("/"+p))
 
@@ -115,7 +174,8 @@ func Static(config StaticConfig) zest.MiddlewareFunc {
 				// 这对于 SPA (单页应用) 前端路由非常重要
 				var he *zest.HTTPError
 				if config.HTML5 && (os.IsNotExist(err) || (errors.As(err, &he) && he.Code == http.StatusNotFound)) {
-					file, err = config.Filesystem.Open(path.Join(config.Root, config.Index))
+					name = path.Join(config.Root, config.Index)
+					file, err = config.Filesystem.Open(name)
 					if err != nil {
 						// index.html 也不存在，那只能返回最初的 404 错误了
 						return next(c)
@@ -138,29 +198,130 @@ func Static(config StaticConfig) zest.MiddlewareFunc {
 				if err == nil {
 					defer indexFile.Close()
 					if indexInfo, err := indexFile.Stat(); err == nil {
-						http.ServeContent(c.ResponseWriter(), c.Request, indexInfo.Name(), indexInfo.ModTime(), indexFile)
+						serveFile(c, &config, cacheControl, indexName, indexInfo, indexFile)
 						return nil
 					}
 				}
 
 				// 开启目录浏览
 				if config.Browse {
-					return listDir(t, name, file, c)
+					return listDir(t, name, file, c, config.ShowHidden)
 				}
 				return next(c)
 			}
 
-			http.ServeContent(c.ResponseWriter(), c.Request, info.Name(), info.ModTime(), file)
+			serveFile(c, &config, cacheControl, name, info, file)
 			return nil
 		}
 	}
 }
 
-func listDir(t *template.Template, name string, dir http.File, c *zest.Context) error {
+// serveFile 输出文件并设置 Cache-Control，开启 Precompressed 时优先尝试预压缩文件
+func serveFile(c *zest.Context, config *StaticConfig, cacheControl string, name string, info fs.FileInfo, file http.File) {
+	if cc, ok := config.CacheControlByExt[strings.ToLower(path.Ext(name))]; ok {
+		cacheControl = cc
+	}
+	if cacheControl != "" {
+		c.SetHeader(zest.HeaderCacheControl, cacheControl)
+	}
+
+	if !config.Precompressed {
+		serveContent(c, info, file)
+		return
+	}
+
+	c.Response().Header().Add(zest.HeaderVary, zest.HeaderAcceptEncoding)
+	acceptEncoding := c.Request.Header.Get(zest.HeaderAcceptEncoding)
+	for _, pc := range precompressedEncodings {
+		if !acceptsEncoding(acceptEncoding, pc.encoding) {
+			continue
+		}
+		compressed, err := config.Filesystem.Open(name + pc.ext)
+		if err != nil {
+			continue
+		}
+		defer compressed.Close()
+		compressedInfo, err := compressed.Stat()
+		if err != nil || compressedInfo.IsDir() {
+			continue
+		}
+
+		// Content-Type 以原文件为准，否则 ServeContent 会按 .gz/.br 扩展名推断
+		ctype, err := contentType(info.Name(), file)
+		if err != nil {
+			break
+		}
+		c.SetHeader(zest.HeaderContentType, ctype)
+		c.SetHeader(zest.HeaderContentEncoding, pc.encoding)
+		serveContent(c, compressedInfo, compressed)
+		return
+	}
+
+	serveContent(c, info, file)
+}
+
+// serveContent 通过 http.ServeContent 输出文件
+// 文件没有修改时间时（如 embed.FS），根据文件大小设置一个弱 ETag
+func serveContent(c *zest.Context, info fs.FileInfo, file io.ReadSeeker) {
+	if info.ModTime().IsZero() && c.Response().Header().Get("ETag") == "" {
+		c.SetHeader("ETag", `W/"`+strconv.FormatInt(info.Size(), 16)+`"`)
+	}
+	http.ServeContent(c.Response(), c.Request, info.Name(), info.ModTime(), file)
+}
+
+// hasDotDot 判断路径中是否包含 .. 段
+func hasDotDot(p string) bool {
+	for seg := range strings.SplitSeq(strings.ReplaceAll(p, "\\", "/"), "/") {
+		if seg == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDotfile 判断路径中是否有以 . 开头的段
+func hasDotfile(p string) bool {
+	for seg := range strings.SplitSeq(p, "/") {
+		if strings.HasPrefix(seg, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// contentType 按扩展名推断 Content-Type，推断不出时读取文件开头嗅探，与 http.ServeContent 的逻辑一致
+func contentType(name string, file io.ReadSeeker) (string, error) {
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		return ctype, nil
+	}
+	var buf [512]byte
+	n, _ := io.ReadFull(file, buf[:])
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// listDir 输出目录列表，目录在前，同类按名称排序
+func listDir(t *template.Template, name string, dir http.File, c *zest.Context, showHidden bool) error {
 	files, err := dir.Readdir(-1)
 	if err != nil {
 		return err
 	}
+	if !showHidden {
+		files = slices.DeleteFunc(files, func(f fs.FileInfo) bool {
+			return strings.HasPrefix(f.Name(), ".")
+		})
+	}
+	slices.SortFunc(files, func(a, b fs.FileInfo) int {
+		if a.IsDir() != b.IsDir() {
+			if a.IsDir() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name(), b.Name())
+	})
 
 	c.SetHeader(zest.HeaderContentType, zest.MIMETextHTMLCharsetUTF8)
 	c.SetStatus(http.StatusOK)
@@ -174,13 +335,18 @@ func listDir(t *template.Template, name string, dir http.File, c *zest.Context)
 
 	for _, f := range files {
 		data.Files = append(data.Files, struct {
-			Name  string
-			IsDir bool
-			Size  string
+			Name    string
+			Href    string
+			IsDir   bool
+			Size    string
+			ModTime string
 		}{
-			Name:  f.Name(),
-			IsDir: f.IsDir(),
-			Size:  formatSize(f.Size()),
+			Name: f.Name(),
+			// 文件名中的空格、#、? 等字符需要转义，否则链接会被截断
+			Href:    url.PathEscape(f.Name()),
+			IsDir:   f.IsDir(),
+			Size:    formatSize(f.Size()),
+			ModTime: f.ModTime().Format(time.DateTime),
 		})
 	}
 	return t.Execute(c.Response(), data)
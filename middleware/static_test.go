@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lemonc7/zest"
+)
+
+func TestStaticDotfiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"secret.txt":         "secret",
+		"public/hello.txt":   "hello",
+		"public/.env":        "TOKEN=1",
+		"public/.git/config": "[core]",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := filepath.Join(dir, "public")
+
+	tests := []struct {
+		name          string
+		allowDotfiles bool
+		target        string
+		wantStatus    int
+		wantBody      string
+	}{
+		{"file", false, "/hello.txt", http.StatusOK, "hello"},
+		{"dotfile falls through", false, "/.env", http.StatusNotFound, ""},
+		{"dot directory falls through", false, "/.git/config", http.StatusNotFound, ""},
+		{"dotfile allowed", true, "/.env", http.StatusOK, "TOKEN=1"},
+		{"encoded traversal", false, "/%2e%2e%2fsecret.txt", http.StatusBadRequest, ""},
+		{"encoded traversal with dotfiles allowed", true, "/%2e%2e/secret.txt", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := zest.New()
+			z.Use(Static(StaticConfig{Root: root, AllowDotfiles: tt.allowDotfiles}))
+
+			rec := z.TestRequest(http.MethodGet, tt.target, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if body := rec.Body.String(); body == "secret" || body == "[core]" {
+				t.Errorf("body leaks a protected file: %q", body)
+			}
+		})
+	}
+}
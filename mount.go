@@ -0,0 +1,60 @@
+package zest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Mount 将一个独立的子应用挂载到 prefix 下，prefix 及其下的所有标准方法的请求都交给 sub 处理
+// 转发前会去掉路径中的 prefix，sub 按挂载前的路径注册路由即可：挂载到 /admin 后，/admin/users 匹配 sub 的 "GET /users"
+// 父应用的全局中间件在外层执行，sub 自己的中间件、错误处理器、NotFound 和 MethodNotAllowed 在挂载范围内生效
+// 挂载范围内的 404 和 405 由 sub 处理，不会再回到父应用；父应用中更具体的路由（如 "GET /admin/health"）仍然优先匹配
+// prefix 必须是不含通配符的固定路径，且不能是 "/"；sub 中 ServeMux 和 RedirectTrailingSlash 生成的重定向地址不包含 prefix
+func (z *Zest) Mount(prefix string, sub *Zest) {
+	prefix = strings.TrimSuffix(cleanPattern(prefix), "/")
+	if prefix == "" || strings.Contains(prefix, "{") {
+		panic("zest: invalid mount prefix " + `"` + prefix + `"`)
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		c := r.Context().Value(contextKey).(*Context)
+		c.sync(w, r)
+
+		u := *r.URL
+		u.Path = strings.TrimPrefix(r.URL.Path, prefix)
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		if rp, ok := strings.CutPrefix(r.URL.RawPath, prefix); ok {
+			u.RawPath = rp
+		} else {
+			u.RawPath = ""
+		}
+		r2 := *r
+		r2.URL = &u
+
+		// 经过 c.Response() 写入，父应用的日志等中间件能看到子应用写出的状态码和大小
+		sub.ServeHTTP(c.Response(), &r2)
+	}
+
+	// 逐个方法注册，不带方法的模式会和父应用中 "GET /" 这类路由冲突
+	// 挂载的路由同样出现在 Routes 中，与已有路由重复时给出明确的 panic 信息
+	name := "zest.Mount(" + prefix + ")"
+	for _, method := range mountMethods {
+		z.register(method, prefix, name, handler)
+		z.register(method, prefix+"/", name, handler)
+	}
+}
+
+// mountMethods Mount 转发的方法，其他方法的请求由父应用按 404 或 405 处理
+// 不单独注册 HEAD：ServeMux 会把 HEAD 请求交给 GET 模式，单独注册反而会和父应用的 GET 路由冲突
+var mountMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodConnect,
+	http.MethodTrace,
+}
@@ -0,0 +1,90 @@
+package zest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMount(t *testing.T) {
+	sub := New()
+	sub.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("X-Sub", "1")
+			return next(c)
+		}
+	})
+	sub.GET("/{$}", func(c *Context) error { return c.String(http.StatusOK, "sub index") })
+	sub.GET("/users/{id}", func(c *Context) error { return c.String(http.StatusOK, "sub user "+c.Param("id")) })
+	sub.NotFound(func(c *Context) error { return c.String(http.StatusNotFound, "sub not found") })
+
+	z := New()
+	z.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("X-Parent", "1")
+			return next(c)
+		}
+	})
+	z.GET("/{$}", func(c *Context) error { return c.String(http.StatusOK, "parent index") })
+	z.GET("/admin/health", func(c *Context) error { return c.String(http.StatusOK, "parent health") })
+	z.Mount("/admin", sub)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantBody   string
+		wantSub    bool
+	}{
+		{"parent route", http.MethodGet, "/", http.StatusOK, "parent index", false},
+		{"mount root", http.MethodGet, "/admin", http.StatusOK, "sub index", true},
+		{"mount root slash", http.MethodGet, "/admin/", http.StatusOK, "sub index", true},
+		{"sub route", http.MethodGet, "/admin/users/5", http.StatusOK, "sub user 5", true},
+		{"parent route wins inside prefix", http.MethodGet, "/admin/health", http.StatusOK, "parent health", false},
+		{"not found inside mount", http.MethodGet, "/admin/missing", http.StatusNotFound, "sub not found", true},
+		{"method not allowed inside mount", http.MethodPost, "/admin/users/5", http.StatusMethodNotAllowed, "method not allowed", true},
+		{"not found outside mount", http.MethodGet, "/missing", http.StatusNotFound, "not found", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := z.TestRequest(tt.method, tt.target, nil)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want to contain %q", rec.Body.String(), tt.wantBody)
+			}
+			if rec.Header().Get("X-Parent") != "1" {
+				t.Error("parent middleware did not run")
+			}
+			if got := rec.Header().Get("X-Sub") == "1"; got != tt.wantSub {
+				t.Errorf("sub middleware ran = %v, want %v", got, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestMountRoutes(t *testing.T) {
+	z := New()
+	z.Mount("/admin", New())
+
+	found := false
+	for _, r := range z.Routes() {
+		if r.Method == http.MethodGet && r.Pattern == "/admin/" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("mounted prefix is missing from Routes")
+	}
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, `"GET /admin"`) {
+			t.Errorf("panic = %q, want a duplicate route message", msg)
+		}
+	}()
+	z.GET("/admin", func(c *Context) error { return nil })
+}
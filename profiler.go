@@ -0,0 +1,24 @@
+package zest
+
+import "net/http/pprof"
+
+// EnableProfiler 在 prefix 下注册 net/http/pprof 的处理器，prefix 为空时使用 "/debug/pprof"
+// 注册的路由有 prefix/、prefix/cmdline、prefix/profile、prefix/symbol、prefix/trace 以及 prefix/heap 等命名 profile
+// pprof 会暴露命令行参数、内存等敏感信息，生产环境中应通过 mws 加上 BasicAuth 或 IP 过滤等访问控制
+func (z *Zest) EnableProfiler(prefix string, mws ...MiddlewareFunc) {
+	if prefix == "" {
+		prefix = "/debug/pprof"
+	}
+	g := z.Group(prefix, mws...)
+
+	g.GET("/{$}", WrapHandlerFunc(pprof.Index))
+	g.GET("/cmdline", WrapHandlerFunc(pprof.Cmdline))
+	g.GET("/profile", WrapHandlerFunc(pprof.Profile))
+	g.GET("/symbol", WrapHandlerFunc(pprof.Symbol))
+	g.POST("/symbol", WrapHandlerFunc(pprof.Symbol))
+	g.GET("/trace", WrapHandlerFunc(pprof.Trace))
+	// pprof.Index 只能识别 /debug/pprof/ 下的命名 profile，自定义前缀时需要单独注册
+	g.GET("/{name}", func(c *Context) error {
+		return WrapHandler(pprof.Handler(c.Param("name")))(c)
+	})
+}
@@ -0,0 +1,142 @@
+package zest
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sync"
+)
+
+// ErrRendererNotRegistered 调用 c.Render 时引擎没有设置 Renderer
+var ErrRendererNotRegistered = errors.New("zest: renderer not registered")
+
+// Renderer 模板渲染接口，通过 z.Renderer 设置后由 c.Render 调用
+type Renderer interface {
+	Render(w io.Writer, name string, data any, c *Context) error
+}
+
+// Render 使用引擎的 Renderer 渲染模板 name 并输出 HTML 响应
+// 先完整渲染到内存再写入响应，模板执行出错时不会写出半个页面
+func (c *Context) Render(status int, name string, data any) error {
+	if c.zest == nil || c.zest.Renderer == nil {
+		return ErrRendererNotRegistered
+	}
+	var buf bytes.Buffer
+	if err := c.zest.Renderer.Render(&buf, name, data, c); err != nil {
+		return err
+	}
+	c.SetHeader(HeaderContentType, MIMETextHTMLCharsetUTF8)
+	c.SetStatus(status)
+	_, err := c.response.Write(buf.Bytes())
+	return err
+}
+
+// TemplateRenderer 基于 html/template 的 Renderer，支持布局和局部模板
+// 设置布局后，c.Render(200, "page.html", data) 把 page.html 中 {{define "content"}}...{{end}} 的内容
+// 填入布局的 {{block "content" .}}{{end}} 位置；没有设置布局时直接执行页面模板
+// 解析后的模板按页面缓存，开启 Reload 后每次渲染都重新读取文件
+type TemplateRenderer struct {
+	// Dir 页面模板所在目录，c.Render 的 name 相对于该目录
+	Dir string
+	// Funcs 模板中可用的函数，需要在第一次渲染之前设置
+	Funcs template.FuncMap
+	// Reload 每次渲染都重新解析布局和页面模板，开发时修改模板无需重启
+	// 生产环境应保持 false，模板只解析一次
+	Reload bool
+
+	mu       sync.RWMutex
+	layout   string
+	partials []string
+	cache    map[string]*template.Template
+}
+
+// NewTemplateRenderer 创建从 dir 目录读取页面模板的 TemplateRenderer
+func NewTemplateRenderer(dir string) *TemplateRenderer {
+	return &TemplateRenderer{
+		Dir:   dir,
+		cache: make(map[string]*template.Template),
+	}
+}
+
+// ParseLayout 设置基础布局 path，partials 为局部模板的 glob（如 "templates/partials/*.html"），与布局一起解析
+// 会立即解析一次以尽早发现语法错误，并清空已缓存的页面模板
+func (r *TemplateRenderer) ParseLayout(path string, partials ...string) error {
+	if _, err := r.parseLayout(path, partials); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.layout = path
+	r.partials = partials
+	clear(r.cache)
+	return nil
+}
+
+// Render 实现 Renderer
+func (r *TemplateRenderer) Render(w io.Writer, name string, data any, c *Context) error {
+	t, err := r.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	layout := r.layout
+	r.mu.RUnlock()
+	if layout == "" {
+		return t.ExecuteTemplate(w, filepath.Base(name), data)
+	}
+	return t.ExecuteTemplate(w, filepath.Base(layout), data)
+}
+
+// lookup 返回页面 name 对应的模板，未开启 Reload 时使用缓存
+func (r *TemplateRenderer) lookup(name string) (*template.Template, error) {
+	if !r.Reload {
+		r.mu.RLock()
+		t, ok := r.cache[name]
+		r.mu.RUnlock()
+		if ok {
+			return t, nil
+		}
+	}
+
+	r.mu.RLock()
+	layout, partials := r.layout, r.partials
+	r.mu.RUnlock()
+
+	var t *template.Template
+	var err error
+	if layout == "" {
+		t = template.New(filepath.Base(name)).Funcs(r.Funcs)
+	} else if t, err = r.parseLayout(layout, partials); err != nil {
+		return nil, err
+	}
+	if t, err = t.ParseFiles(filepath.Join(r.Dir, name)); err != nil {
+		return nil, err
+	}
+
+	if !r.Reload {
+		r.mu.Lock()
+		if r.cache == nil {
+			r.cache = make(map[string]*template.Template)
+		}
+		r.cache[name] = t
+		r.mu.Unlock()
+	}
+	return t, nil
+}
+
+// parseLayout 解析布局和局部模板，每个页面都在一份新解析的布局上定义自己的 content，互不影响
+func (r *TemplateRenderer) parseLayout(path string, partials []string) (*template.Template, error) {
+	t, err := template.New(filepath.Base(path)).Funcs(r.Funcs).ParseFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range partials {
+		if t, err = t.ParseGlob(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,139 @@
+package zest
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTemplates 在 dir 下写入模板文件，key 为相对路径
+func writeTemplates(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// templateApp 创建使用 layout、partials 和 pages 目录的引擎
+func templateApp(t *testing.T, dir string, reload bool) *Zest {
+	t.Helper()
+	r := NewTemplateRenderer(filepath.Join(dir, "pages"))
+	r.Reload = reload
+	r.Funcs = template.FuncMap{"upper": strings.ToUpper}
+	if err := r.ParseLayout(filepath.Join(dir, "layout.html"), filepath.Join(dir, "partials", "*.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	z := New()
+	z.Renderer = r
+	z.GET("/{page}", func(c *Context) error {
+		return c.Render(http.StatusOK, c.Param("page")+".html", Map{"Name": "zest"})
+	})
+	return z
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplates(t, dir, map[string]string{
+		"layout.html":       `<main>{{template "nav" .}}|{{block "content" .}}default{{end}}</main>`,
+		"partials/nav.html": `{{define "nav"}}<nav>{{upper .Name}}</nav>{{end}}`,
+		"pages/home.html":   `{{define "content"}}home {{.Name}}{{end}}`,
+		"pages/about.html":  `{{define "content"}}about <b>{{.Name}}</b>{{end}}`,
+		"pages/empty.html":  ``,
+	})
+	z := templateApp(t, dir, false)
+
+	tests := []struct {
+		page       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"home", http.StatusOK, "<main><nav>ZEST</nav>|home zest</main>"},
+		{"about", http.StatusOK, "<main><nav>ZEST</nav>|about <b>zest</b></main>"},
+		// 先渲染过 home 和 about 之后，没有定义 content 的页面仍然使用布局的默认内容
+		{"empty", http.StatusOK, "<main><nav>ZEST</nav>|default</main>"},
+		{"home", http.StatusOK, "<main><nav>ZEST</nav>|home zest</main>"},
+		{"missing", http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			rec := z.TestRequest(http.MethodGet, "/"+tt.page, nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %q", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if got := rec.Header().Get(HeaderContentType); got != MIMETextHTMLCharsetUTF8 {
+				t.Errorf("Content-Type = %q, want %q", got, MIMETextHTMLCharsetUTF8)
+			}
+		})
+	}
+}
+
+func TestTemplateRendererReload(t *testing.T) {
+	tests := []struct {
+		name   string
+		reload bool
+		want   string
+	}{
+		{"cached", false, "<main>v1</main>"},
+		{"reload", true, "<main>v2</main>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTemplates(t, dir, map[string]string{
+				"layout.html":       `<main>{{block "content" .}}{{end}}</main>`,
+				"partials/nav.html": `{{define "nav"}}{{end}}`,
+				"pages/home.html":   `{{define "content"}}v1{{end}}`,
+			})
+			z := templateApp(t, dir, tt.reload)
+
+			if rec := z.TestRequest(http.MethodGet, "/home", nil); rec.Body.String() != "<main>v1</main>" {
+				t.Fatalf("first render = %q", rec.Body.String())
+			}
+			writeTemplates(t, dir, map[string]string{"pages/home.html": `{{define "content"}}v2{{end}}`})
+
+			if rec := z.TestRequest(http.MethodGet, "/home", nil); rec.Body.String() != tt.want {
+				t.Errorf("after change = %q, want %q", rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateRendererWithoutLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplates(t, dir, map[string]string{"page.html": `<p>{{.}}</p>`})
+
+	z := New()
+	z.Renderer = NewTemplateRenderer(dir)
+	z.GET("/", func(c *Context) error { return c.Render(http.StatusCreated, "page.html", "<hi>") })
+
+	rec := z.TestRequest(http.MethodGet, "/", nil)
+	if rec.Code != http.StatusCreated || rec.Body.String() != "<p>&lt;hi&gt;</p>" {
+		t.Errorf("response = %d %q, want 201 %q", rec.Code, rec.Body.String(), "<p>&lt;hi&gt;</p>")
+	}
+}
+
+func TestRenderWithoutRenderer(t *testing.T) {
+	c := New().NewContext(nil, nil)
+	if err := c.Render(http.StatusOK, "page.html", nil); !errors.Is(err, ErrRendererNotRegistered) {
+		t.Errorf("Render error = %v, want ErrRendererNotRegistered", err)
+	}
+}
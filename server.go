@@ -0,0 +1,281 @@
+package zest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func (z *Zest) Run(addr string) error {
+	if err := z.runStartHooks(); err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", listenAddr(addr, ":http"))
+	if err != nil {
+		return err
+	}
+	return z.serve(addr, ln)
+}
+
+// RunListener 在已经创建好的 ln 上启动 HTTP 服务，如 systemd socket 激活传入的监听器、包装了 PROXY 协议的监听器
+// 与 Run 共用 z.Server、启动钩子和优雅关闭，服务停止时 ln 会被关闭
+func (z *Zest) RunListener(ln net.Listener) error {
+	if err := z.runStartHooks(); err != nil {
+		ln.Close()
+		return err
+	}
+	return z.serve(ln.Addr().String(), ln)
+}
+
+// RunUnix 在 unix domain socket path 上启动 HTTP 服务，通常用于放在同一台机器的 Nginx 等反向代理之后
+// 启动前删除上次异常退出残留的 socket 文件，创建后把权限设置为 UnixSocketMode；Shutdown 关闭监听器时 socket 文件随之删除
+func (z *Zest) RunUnix(path string) error {
+	if err := z.runStartHooks(); err != nil {
+		return err
+	}
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, z.UnixSocketMode); err != nil {
+		ln.Close()
+		return err
+	}
+	return z.serve(path, ln)
+}
+
+// serve 输出启动信息后在 ln 上处理请求
+func (z *Zest) serve(addr string, ln net.Listener) error {
+	z.banner("🚀", ln, "")
+	err := z.newServer(addr).Serve(ln)
+	// 通过 Shutdown 正常关闭时不视为错误
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// RunTLS 使用证书文件启动 HTTPS 服务
+func (z *Zest) RunTLS(addr, certFile, keyFile string) error {
+	if err := z.runStartHooks(); err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", listenAddr(addr, ":https"))
+	if err != nil {
+		return err
+	}
+	z.banner("🔒", ln, "TLS")
+	srv := z.newServer(addr)
+	srv.TLSConfig = defaultTLSConfig()
+	err = srv.ServeTLS(ln, certFile, keyFile)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// RunAutoTLS 通过 Let's Encrypt 自动申请证书并启动 HTTPS 服务
+// 只为 domains 中列出的域名申请证书，证书缓存在 AutoTLSCacheDir 目录中
+// 使用 TLS-ALPN-01 验证，addr 需要能从公网的 443 端口访问
+func (z *Zest) RunAutoTLS(addr string, domains ...string) error {
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(z.AutoTLSCacheDir),
+	}
+
+	if err := z.runStartHooks(); err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", listenAddr(addr, ":https"))
+	if err != nil {
+		return err
+	}
+	z.banner("🔒", ln, "AutoTLS")
+	srv := z.newServer(addr)
+	srv.TLSConfig = defaultTLSConfig()
+	srv.TLSConfig.GetCertificate = m.GetCertificate
+	srv.TLSConfig.NextProtos = append(srv.TLSConfig.NextProtos, "h2", "http/1.1", acme.ALPNProto)
+	err = srv.ServeTLS(ln, "", "")
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// listenAddr addr 为空时使用 def，与 http.Server 的默认行为一致
+func listenAddr(addr, def string) string {
+	if addr == "" {
+		return def
+	}
+	return addr
+}
+
+// banner 输出启动信息：实际监听的地址（端口为 0 时是系统分配的端口）、路由数量和 Go 版本
+// HideBanner 为 true 时不输出，设置了 BannerWriter 时写入 BannerWriter，否则通过标准库 log 输出
+func (z *Zest) banner(icon string, ln net.Listener, mode string) {
+	if z.HideBanner {
+		return
+	}
+	info := fmt.Sprintf("%d routes, %s", len(z.Routes()), runtime.Version())
+	if mode != "" {
+		info = mode + ", " + info
+	}
+	msg := fmt.Sprintf("%s Zest server listening on %s (%s)", icon, ln.Addr(), info)
+	if z.BannerWriter != nil {
+		fmt.Fprintln(z.BannerWriter, msg)
+		return
+	}
+	log.Println(msg)
+}
+
+// defaultTLSConfig 默认的 TLS 配置，最低 TLS 1.2，只启用支持前向保密的 AEAD 加密套件
+// TLS 1.3 的加密套件不可配置，CipherSuites 只对 TLS 1.2 生效
+func defaultTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{
+			tls.X25519,
+			tls.CurveP256,
+		},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+}
+
+// RunWithGracefulShutdown 启动服务并监听退出信号（默认 SIGINT、SIGTERM）
+// 收到信号后停止接收新请求，并在 ShutdownTimeout 内等待处理中的请求完成
+func (z *Zest) RunWithGracefulShutdown(addr string, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), signals...)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- z.Run(addr)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	// 恢复默认的信号处理，再次收到信号时直接退出
+	stop()
+
+	log.Println("🛑 Zest server shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), z.ShutdownTimeout)
+	defer cancel()
+	return z.Shutdown(shutdownCtx)
+}
+
+// Shutdown 优雅关闭服务
+// 停止接收新连接，等待处理中的请求完成（最长到 ctx 的截止时间），
+// 然后按注册的逆序执行 OnStop 注册的函数，最后依次执行 OnShutdown 注册的函数
+func (z *Zest) Shutdown(ctx context.Context) error {
+	z.serverMu.Lock()
+	if z.Server == nil {
+		z.Server = &http.Server{}
+	}
+	srv := z.Server
+	hooks := z.onShutdown
+	stopHooks := z.onStop
+	z.serverMu.Unlock()
+
+	// 在 Run 启动之前调用时，之后的 Run 复用同一个 Server，会直接返回而不是继续提供服务
+	err := srv.Shutdown(ctx)
+	// 后启动的资源可能依赖先启动的资源，所以逆序关闭
+	for i := len(stopHooks) - 1; i >= 0; i-- {
+		if hookErr := stopHooks[i](ctx); hookErr != nil {
+			log.Printf("⚠️ Zest stop hook error: %v\n", hookErr)
+		}
+	}
+	for _, fn := range hooks {
+		fn()
+	}
+	return err
+}
+
+// OnStart 注册服务开始接收请求之前执行的函数，例如预热缓存、连接数据库
+// Run 系列方法（包括 RunListener、RunUnix）会按注册顺序执行，任意一个返回错误时停止启动并返回该错误
+func (z *Zest) OnStart(fn func() error) {
+	z.serverMu.Lock()
+	defer z.serverMu.Unlock()
+	z.onStart = append(z.onStart, fn)
+}
+
+// OnStop 注册服务关闭时执行的函数，在 Shutdown 中按注册的逆序执行
+// ctx 为 Shutdown 的 ctx，返回的错误只会被记录到日志，不影响其他函数的执行
+func (z *Zest) OnStop(fn func(ctx context.Context) error) {
+	z.serverMu.Lock()
+	defer z.serverMu.Unlock()
+	z.onStop = append(z.onStop, fn)
+}
+
+// runStartHooks 解析 TrustedProxies 后按注册顺序执行 OnStart 注册的函数
+// Run 系列方法启动前都会调用，配置错误在这里返回而不是等到处理请求时才发现
+func (z *Zest) runStartHooks() error {
+	if err := z.loadTrustedProxies(); err != nil {
+		return err
+	}
+
+	z.serverMu.Lock()
+	hooks := z.onStart
+	z.serverMu.Unlock()
+
+	for _, fn := range hooks {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnShutdown 注册服务关闭时执行的函数，例如关闭数据库连接池
+// 这些函数在处理中的请求完成之后按注册顺序执行
+func (z *Zest) OnShutdown(fn func()) {
+	z.serverMu.Lock()
+	defer z.serverMu.Unlock()
+	z.onShutdown = append(z.onShutdown, fn)
+}
+
+// newServer 返回配置好地址和处理器的 Server
+// Run 和 Shutdown 通常在不同的 goroutine 中调用，z.Server 的读写都在 serverMu 下进行
+func (z *Zest) newServer(addr string) *http.Server {
+	z.serverMu.Lock()
+	if z.Server == nil {
+		z.Server = &http.Server{}
+	}
+	srv := z.Server
+	srv.Addr = addr
+	srv.Handler = z
+	z.serverMu.Unlock()
+	if z.Debug {
+		z.PrintRoutes(os.Stdout)
+	}
+	return srv
+}
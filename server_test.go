@@ -0,0 +1,76 @@
+package zest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	tests := []struct {
+		name           string
+		nilServer      bool
+		shutdownBefore bool
+	}{
+		{"shutdown while running", false, false},
+		{"shutdown while running without preset server", true, false},
+		{"shutdown before run", false, true},
+		{"shutdown before run without preset server", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := New()
+			z.HideBanner = true
+			if tt.nilServer {
+				z.Server = nil
+			}
+			stopped := make(chan struct{})
+			z.OnShutdown(func() { close(stopped) })
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if tt.shutdownBefore {
+				if err := z.Shutdown(ctx); err != nil {
+					t.Fatalf("Shutdown = %v", err)
+				}
+			}
+
+			done := make(chan error, 1)
+			go func() { done <- z.RunListener(ln) }()
+
+			if !tt.shutdownBefore {
+				// Run 和 Shutdown 在不同的 goroutine 中访问 z.Server，-race 下不能报告数据竞争
+				if err := z.Shutdown(ctx); err != nil {
+					t.Fatalf("Shutdown = %v", err)
+				}
+			}
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("RunListener = %v, want nil", err)
+				}
+			case <-time.After(5 * time.Second):
+				// Shutdown 早于 Serve 时可能还没有生效，这里再关闭一次，避免测试挂起
+				z.Server.Close()
+				t.Fatal("RunListener did not return after Shutdown")
+			}
+			select {
+			case <-stopped:
+			default:
+				t.Error("OnShutdown hook did not run")
+			}
+			if _, err := http.Get("http://" + ln.Addr().String()); err == nil {
+				t.Error("server still accepts requests after Shutdown")
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package zest
+
+import (
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+//go:embed testdata/static
+var embedded embed.FS
+
+func TestStaticEmbed(t *testing.T) {
+	z := New()
+	var status int
+	var size int64
+	z.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			err := next(c)
+			status, size = c.Response().Status, c.Response().Size
+			return err
+		}
+	})
+	z.StaticFS("/assets", embedded)
+	z.FileFS("/hello", "testdata/static/hello.txt", embedded)
+
+	// embed.FS 没有修改时间，ETag 由文件大小生成
+	helloETag := `W/"` + strconv.FormatInt(int64(len("hello embed\n")), 16) + `"`
+
+	tests := []struct {
+		name        string
+		target      string
+		ifNoneMatch string
+		wantStatus  int
+		wantBody    string
+		wantETag    string
+	}{
+		{"FileFS", "/hello", "", http.StatusOK, "hello embed\n", helloETag},
+		{"FileFS not modified", "/hello", helloETag, http.StatusNotModified, "", helloETag},
+		{"StaticFS file", "/assets/testdata/static/hello.txt", "", http.StatusOK, "hello embed\n", helloETag},
+		{"StaticFS nested file", "/assets/testdata/static/css/app.css", "", http.StatusOK, "body{}\n", `W/"7"`},
+		{"StaticFS not modified", "/assets/testdata/static/hello.txt", helloETag, http.StatusNotModified, "", helloETag},
+		{"StaticFS missing", "/assets/testdata/static/missing.txt", "", http.StatusNotFound, "404 page not found\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := z.Test(req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := rec.Header().Get(HeaderLastModified); got != "" {
+				t.Errorf("Last-Modified = %q, want none", got)
+			}
+			// 写入经过 c.Response()，中间件看到的状态码和大小与实际响应一致
+			if status != tt.wantStatus || size != int64(len(tt.wantBody)) {
+				t.Errorf("Response() = %d/%d B, want %d/%d B", status, size, tt.wantStatus, len(tt.wantBody))
+			}
+		})
+	}
+}
+
+// staticRoot 创建包含普通文件、dotfile 和根目录外文件的临时目录，返回作为静态根目录的 public 子目录
+func staticRoot(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"secret.txt":             "secret",
+		"public/hello.txt":       "hello",
+		"public/.env":            "TOKEN=1",
+		"public/.git/config":     "[core]",
+		"public/.well/known.txt": "known",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "public")
+}
+
+func TestStaticDotfiles(t *testing.T) {
+	root := staticRoot(t)
+
+	tests := []struct {
+		name         string
+		denyDotfiles bool
+		target       string
+		wantStatus   int
+		wantBody     string
+	}{
+		{"file", true, "/files/hello.txt", http.StatusOK, "hello"},
+		{"dotfile", true, "/files/.env", http.StatusNotFound, ""},
+		{"dot directory", true, "/files/.git/config", http.StatusNotFound, ""},
+		{"dotfile allowed", false, "/files/.env", http.StatusOK, "TOKEN=1"},
+		// ServeMux 先清理路径并重定向到 /secret.txt，不会进入静态文件处理函数
+		{"traversal", true, "/files/../secret.txt", http.StatusTemporaryRedirect, ""},
+		{"encoded traversal", true, "/files/%2e%2e%2fsecret.txt", http.StatusBadRequest, ""},
+		{"encoded backslash traversal", true, "/files/..%5csecret.txt", http.StatusBadRequest, ""},
+		{"traversal with dotfiles allowed", false, "/files/%2e%2e%2fsecret.txt", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := New()
+			z.DenyDotfiles = tt.denyDotfiles
+			z.Static("/files", root)
+
+			rec := z.TestRequest(http.MethodGet, tt.target, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if body := rec.Body.String(); body == "secret" || body == "[core]" {
+				t.Errorf("body leaks a protected file: %q", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package zest
+
+import "expvar"
+
+// Stats 引擎的运行时统计
+type Stats struct {
+	// Requests 已接收的请求总数
+	Requests uint64 `json:"requests"`
+	// Active 正在处理的请求数
+	Active int64 `json:"active"`
+	// Panics middleware.Recovery 捕获的 panic 次数
+	Panics uint64 `json:"panics"`
+}
+
+// Stats 返回当前的运行时统计，计数器基于原子操作，可以在任意 goroutine 中调用
+// 需要对外暴露时自行注册处理器，例如 z.GET("/stats", func(c *zest.Context) error { return c.JSON(200, z.Stats()) })
+func (z *Zest) Stats() Stats {
+	return Stats{
+		Requests: z.stats.requests.Load(),
+		Active:   z.stats.active.Load(),
+		Panics:   z.stats.panics.Load(),
+	}
+}
+
+// RecordPanic 将 panic 计数加一，由 middleware.Recovery 在捕获 panic 后调用
+func (z *Zest) RecordPanic() {
+	z.stats.panics.Add(1)
+}
+
+// PublishExpvar 将 Stats 以 name 发布到 expvar，可通过 /debug/vars 查看
+// 与 expvar.Publish 一样，name 重复时会 panic
+func (z *Zest) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return z.Stats()
+	}))
+}
@@ -0,0 +1,33 @@
+package zest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// NewContext 创建绑定到 z 的 Context，可用于在测试中直接调用单个 HandlerFunc
+// c.Error、c.MultipartForm 等依赖引擎配置的方法会使用 z 的设置
+// 直接调用处理器时不会经过路由，c.Param 需要的路径参数可以通过 r.SetPathValue 设置
+func (z *Zest) NewContext(w http.ResponseWriter, r *http.Request) *Context {
+	c := NewContext(w, r)
+	c.zest = z
+	return c
+}
+
+// Test 不启动服务器执行一次请求，返回记录了响应的 httptest.ResponseRecorder
+// 请求会经过全局中间件、路由匹配、路由中间件和错误处理器，与真实请求完全一致
+func (z *Zest) Test(r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	z.ServeHTTP(rec, r)
+	return rec
+}
+
+// TestRequest 构造请求并交给 Test 执行，body 可以为 nil
+// 需要设置请求头时，使用 httptest.NewRequest 构造请求后调用 Test
+//
+//	rec := z.TestRequest(http.MethodGet, "/users/5", nil)
+//	if rec.Code != http.StatusOK { ... }
+func (z *Zest) TestRequest(method, target string, body io.Reader) *httptest.ResponseRecorder {
+	return z.Test(httptest.NewRequest(method, target, body))
+}
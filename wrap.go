@@ -0,0 +1,49 @@
+package zest
+
+import "net/http"
+
+// WrapHandler 将 http.Handler 适配为 HandlerFunc，用于挂载 GraphQL、OAuth 等基于 net/http 的代码
+// 写入经过 c.Response()，被包装的处理器返回的状态码（包括非 200）会记录在 c.Response().Status 中，日志等中间件可以照常读取
+// 响应已经由被包装的处理器写出，所以总是返回 nil，不会再交给错误处理器
+func WrapHandler(h http.Handler) HandlerFunc {
+	return func(c *Context) error {
+		h.ServeHTTP(c.Response(), c.Request)
+		return nil
+	}
+}
+
+// WrapHandlerFunc 将 http.HandlerFunc 适配为 HandlerFunc，见 WrapHandler
+func WrapHandlerFunc(h http.HandlerFunc) HandlerFunc {
+	return WrapHandler(h)
+}
+
+// WrapMiddleware 将标准的 net/http 中间件适配为 MiddlewareFunc
+// 中间件传给下一层的 *http.Request 和 http.ResponseWriter 会替换到 Context 上，
+// 所以它通过 context 注入的值、包装的 ResponseWriter（如压缩）对后续处理器同样生效
+// 中间件自己写出响应（如鉴权失败返回 401）而不调用下一层时，状态码同样会记录到 c.Response()
+func WrapMiddleware(m func(http.Handler) http.Handler) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			res := c.Response()
+			original := res.ResponseWriter
+			// 中间件拿到的是一个独立的 Response，记录它自己直接写出的响应
+			// 不能直接传 res，否则下面替换 res.ResponseWriter 后会形成循环写入
+			outer := &Response{ResponseWriter: original}
+
+			var err error
+			m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.Request = r
+				res.ResponseWriter = w
+				err = next(c)
+			})).ServeHTTP(outer, c.Request)
+
+			res.ResponseWriter = original
+			if !res.Committed && outer.Committed {
+				res.Status = outer.Status
+				res.Size = outer.Size
+				res.Committed = true
+			}
+			return err
+		}
+	}
+}
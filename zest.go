@@ -2,10 +2,23 @@ package zest
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"net/http"
+	"net/netip"
+	"os"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Zest struct {
@@ -13,6 +26,117 @@ type Zest struct {
 	ErrHandler  ErrHandlerFunc
 	middlewares []MiddlewareFunc
 	pool        sync.Pool
+
+	// AutoHead 为每个 GET 路由自动响应 HEAD 请求，默认 true
+	// HEAD 请求会执行与 GET 相同的处理链，但响应体会被丢弃
+	AutoHead bool
+
+	// MultipartMemoryLimit 解析 multipart 表单时最多占用的内存，超出部分写入临时文件
+	// c.Bind、c.FormFile、c.MultipartForm、c.FormParams 等都按这个上限解析，上传大文件的服务可以调小以减少内存占用
+	// 临时文件在请求结束时自动删除；它只控制内存和磁盘的分配，不限制请求体的总大小
+	// 默认 32MB
+	MultipartMemoryLimit int64
+
+	// ResponseBufferSize 大于 0 时，不超过该大小的响应先完整缓冲，请求结束时设置 Content-Length 后一次写出
+	// 默认 0 即不缓冲：net/http 只会为 2KB 以内的响应自动设置 Content-Length，更大的响应以 chunked 编码发送
+	// 开启后每个响应多一次内存拷贝，状态码和响应头推迟到处理链结束才发出；超过上限或调用 Flush 的响应（SSE、JSONStream 等）自动转为直接写出
+	ResponseBufferSize int
+
+	// DenyDotfiles Static 和 StaticFS 拒绝访问以 . 开头的路径（如 /.env、/.git/config），返回 404，默认 true
+	DenyDotfiles bool
+
+	// Debug 开发模式，开启后 c.JSON 输出带缩进的 JSON，启动时输出路由表，默认 false
+	Debug bool
+
+	// RedirectTrailingSlash 请求没有匹配到路由时，去掉或补上末尾的 / 再匹配一次，匹配成功则 308 重定向到该路径
+	// 例如只注册了 /users 时，/users/ 会被重定向到 /users；查询参数会被保留
+	// 使用 308 而不是 301，POST、PUT 等请求重定向后仍保持原方法和请求体；默认 false
+	// 只注册了 /users/ 时，/users 由 ServeMux 自己以 307 重定向到 /users/，同样保持方法和请求体，不受该选项影响
+	RedirectTrailingSlash bool
+
+	// BindBodyLimit c.Bind 读取 JSON、XML 和 urlencoded 表单请求体的大小上限，超出时返回 413
+	// 即使没有安装限制请求体大小的中间件，也能避免一个超大请求在解码时耗尽内存；小于 0 表示不限制
+	// multipart 表单不受该上限限制；单次调用可以用 c.BindWithLimit 覆盖，默认 1MB
+	BindBodyLimit int64
+
+	// DisallowUnknownFields c.Bind 解码 JSON 请求体时拒绝结构体中不存在的字段，返回 400
+	// 只对 DefaultJSONSerializer 生效；默认 false，多余的字段被忽略，单次调用可以用 c.BindStrict
+	DisallowUnknownFields bool
+
+	// Logger c.Logger 的基础 logger，为 nil 时使用 slog.Default()
+	Logger *slog.Logger
+
+	// Renderer c.Render 使用的模板渲染器，默认为 nil，可设置为 NewTemplateRenderer 创建的 TemplateRenderer
+	Renderer Renderer
+
+	// JSONSerializer c.JSON 和 c.Bind 使用的 JSON 编解码器，默认 DefaultJSONSerializer（encoding/json）
+	JSONSerializer JSONSerializer
+
+	// TrustedProxies 可信代理列表，支持 CIDR（10.0.0.0/8）和单个 IP
+	// 只有来自可信代理的请求，c.RealIP、c.Scheme、c.Host 才会读取 X-Forwarded-* / X-Real-Ip，默认为空即完全忽略转发头
+	// 第一次使用时解析一次，之后直接修改该字段不再生效；Run 系列方法启动时解析并返回格式错误，
+	// 不经过 Run 直接使用 ServeHTTP 时格式错误会导致不信任任何代理，需要提前校验时使用 SetTrustedProxies
+	TrustedProxies []string
+
+	// trustedProxies 解析后的 TrustedProxies
+	trustedProxies     []netip.Prefix
+	trustedProxiesOnce sync.Once
+	trustedProxiesErr  error
+	// routes 记录所有注册的路由，用于 Routes 查询
+	routes []*Route
+	// methods 所有路由用到的方法，用于判断 405 和生成 Allow 头
+	methods []string
+
+	// ShutdownTimeout RunWithGracefulShutdown 等待请求处理完成的最长时间，默认 10 秒
+	ShutdownTimeout time.Duration
+	// AutoTLSCacheDir RunAutoTLS 缓存证书的目录，默认 ".cache/autocert"
+	AutoTLSCacheDir string
+	// UnixSocketMode RunUnix 创建的 socket 文件的权限，默认 0660，即同组用户（如 Nginx 所在的组）可以连接
+	UnixSocketMode os.FileMode
+	// HideBanner 不输出启动信息，适合日志由采集器解析的容器环境
+	HideBanner bool
+	// BannerWriter 启动信息的输出位置，默认为 nil，即通过标准库 log 输出
+	BannerWriter io.Writer
+
+	// Server Run 系列方法和 Shutdown 共用的 http.Server
+	// 可在启动前修改 ReadTimeout、WriteTimeout 等参数，Addr 和 Handler 会在启动时被覆盖
+	// 注意 WriteTimeout 同样限制 SSE 等长连接的总时长
+	Server *http.Server
+
+	serverMu   sync.Mutex
+	onShutdown []func()
+	onStart    []func() error
+	onStop     []func(ctx context.Context) error
+
+	// stats 运行时计数器，见 Stats
+	stats struct {
+		requests atomic.Uint64
+		active   atomic.Int64
+		panics   atomic.Uint64
+	}
+
+	// notFoundHandler 和 methodNotAllowedHandler 见 NotFound 和 MethodNotAllowed
+	notFoundHandler         HandlerFunc
+	methodNotAllowedHandler HandlerFunc
+
+	// errorMappings 由 MapError 注册，按注册顺序匹配
+	errorMappings []errorMapping
+}
+
+// errorMapping 错误到状态码的映射
+type errorMapping struct {
+	target error
+	status int
+}
+
+// Route 已注册路由的信息
+type Route struct {
+	Method  string
+	Pattern string
+	// Name 路由名称，可通过注册方法返回的 *Route 设置
+	Name string
+	// Handler 处理器的函数名，通过 runtime.FuncForPC 获取
+	Handler string
 }
 
 type Map map[string]any
@@ -27,8 +151,24 @@ var contextKey = struct{}{}
 
 func New() *Zest {
 	z := &Zest{
-		ErrHandler: DefaultErrHandlerFunc,
-		mux:        http.NewServeMux(),
+		ErrHandler:              DefaultErrHandlerFunc,
+		mux:                     http.NewServeMux(),
+		AutoHead:                true,
+		MultipartMemoryLimit:    defaultMemory,
+		BindBodyLimit:           defaultBindBodyLimit,
+		DenyDotfiles:            true,
+		JSONSerializer:          DefaultJSONSerializer{},
+		ShutdownTimeout:         10 * time.Second,
+		AutoTLSCacheDir:         ".cache/autocert",
+		UnixSocketMode:          0o660,
+		notFoundHandler:         defaultNotFoundHandler,
+		methodNotAllowedHandler: defaultMethodNotAllowedHandler,
+		Server: &http.Server{
+			ReadTimeout:       60 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+		},
 	}
 	z.pool.New = func() any {
 		return NewContext(nil, nil)
@@ -40,25 +180,104 @@ func New() *Zest {
 		c := r.Context().Value(contextKey).(*Context)
 		c.sync(w, r)
 
-		// 通过全局错误处理器返回标准 404
-		z.ErrHandler(c, NewHTTPError(http.StatusNotFound, "not found"))
+		if z.RedirectTrailingSlash {
+			if target, ok := z.trailingSlashTarget(r); ok {
+				if err := c.Redirect(http.StatusPermanentRedirect, target); err != nil {
+					c.Error(err)
+				}
+				return
+			}
+		}
+
+		// 路径能被其他方法的路由匹配时返回 405
+		if methods := z.allowedMethods(r); len(methods) > 0 {
+			z.methodNotAllowed(c, methods)
+			return
+		}
+
+		if err := z.notFoundHandler(c); err != nil {
+			c.Error(err)
+		}
 	})
 
 	return z
 }
 
+// NotFound 设置没有匹配到任何路由时的处理器，默认返回 404 JSON 错误
+// 404 由 New 中注册的 "/" 兜底路由触发，它和普通路由一样位于 ServeMux 内部，
+// 所以全局中间件（Use 注册的）会照常执行，日志能记录到 404 请求；路由和分组的局部中间件则不会执行
+func (z *Zest) NotFound(handler HandlerFunc) {
+	z.notFoundHandler = handler
+}
+
+// MethodNotAllowed 设置路径匹配但方法不匹配时的处理器，默认返回 405 JSON 错误
+// 调用处理器前已经设置好 Allow 响应头；与 NotFound 一样只经过全局中间件
+func (z *Zest) MethodNotAllowed(handler HandlerFunc) {
+	z.methodNotAllowedHandler = handler
+}
+
+// MapError 将 target 错误映射为 status 状态码，使用 errors.Is 匹配，所以被 fmt.Errorf("%w") 包装的错误同样生效
+// 处理器直接返回 gorm.ErrRecordNotFound 之类的错误即可得到 404，不需要手动包装成 HTTPError
+// DefaultErrHandlerFunc 的判断顺序为：HTTPError、MapError 注册的映射（按注册顺序）、500
+func (z *Zest) MapError(target error, status int) {
+	z.errorMappings = append(z.errorMappings, errorMapping{target: target, status: status})
+}
+
+// mappedStatus 返回 err 匹配到的映射状态码
+func (z *Zest) mappedStatus(err error) (int, bool) {
+	for _, m := range z.errorMappings {
+		if errors.Is(err, m.target) {
+			return m.status, true
+		}
+	}
+	return 0, false
+}
+
+func defaultNotFoundHandler(c *Context) error {
+	return NewHTTPError(http.StatusNotFound, "not found")
+}
+
+func defaultMethodNotAllowedHandler(c *Context) error {
+	return NewHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+}
+
 func (z *Zest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	z.stats.requests.Add(1)
+	z.stats.active.Add(1)
+	defer z.stats.active.Add(-1)
+
+	// HEAD 请求的响应体在最底层丢弃，ETag 等中间件看到的响应体与 GET 请求一致
+	if r.Method == http.MethodHead {
+		w = headResponseWriter{w}
+	}
+
+	var bw *bufferedWriter
+	if z.ResponseBufferSize > 0 {
+		bw = acquireBufferedWriter(w, r, z.ResponseBufferSize)
+		defer releaseBufferedWriter(bw)
+		w = bw
+	}
+
 	c := z.pool.Get().(*Context)
 	c.reset(w, r)
 	c.zest = z
-	defer z.pool.Put(c)
+	defer func() {
+		// 中间件和 Context 解析的是 WithContext 之后的请求副本，http.Server 只会清理原始请求的表单，这里删除副本解析出的临时文件
+		if c.Request != nil && c.Request.MultipartForm != nil {
+			_ = c.Request.MultipartForm.RemoveAll()
+		}
+		// 放回池之前清空请求相关的状态，避免池中的 Context 持有已结束请求的 Request、ResponseWriter 和 store 中的值
+		c.reset(nil, nil)
+		z.pool.Put(c)
+	}()
 
 	// 将自定义的 Context 存入上下文中
 	r = r.WithContext(context.WithValue(r.Context(), contextKey, c))
 	c.Request = r
 
 	handle := func(ctx *Context) error {
-		z.mux.ServeHTTP(ctx.ResponseWriter(), ctx.Request)
+		// 直接取字段，ctx.ResponseWriter() 会提前写出中间件通过 SetStatus 设置的状态码
+		z.mux.ServeHTTP(ctx.response.ResponseWriter, ctx.Request)
 		return nil
 	}
 
@@ -67,65 +286,344 @@ func (z *Zest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// 错误处理
 	if err := handle(c); err != nil {
-		z.ErrHandler(c, err)
+		c.Error(err)
+	}
+
+	// 只设置了状态码而没有写入响应体时（如 NoContent、Redirect），在所有中间件执行完之后写出响应头
+	c.response.writePending()
+	if bw != nil {
+		bw.finish()
 	}
 }
 
-func (z *Zest) handle(method string, pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	route := method + " " + pattern
+func (z *Zest) handle(method string, pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	pattern = cleanPattern(pattern)
 
 	// 处理局部路由中间件
 	finalHandler := use(handler, mws...)
 
-	z.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+	return z.register(method, pattern, handlerName(handler), func(w http.ResponseWriter, r *http.Request) {
 		// 此时能进这里的请求，已经经过了 ServeHTTP 里的全局中间件
 		c := r.Context().Value(contextKey).(*Context)
 		c.sync(w, r)
 
+		// 没有显式注册 HEAD 时，ServeMux 会把 HEAD 请求交给 GET 路由处理
+		if method == http.MethodGet && r.Method == http.MethodHead && !z.AutoHead {
+			z.methodNotAllowed(c, z.allowedMethods(r))
+			return
+		}
+
 		if err := finalHandler(c); err != nil {
-			z.ErrHandler(c, err)
+			c.Error(err)
 		}
 	})
 }
 
-func (z *Zest) GET(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	z.handle(http.MethodGet, pattern, handler, mws...)
+// register 把 "method pattern" 注册到 ServeMux，并记录到 Routes 和 405 判断使用的方法列表
+// name 为 Routes 中显示的处理器名称
+func (z *Zest) register(method, pattern, name string, h http.HandlerFunc) *Route {
+	route := method + " " + pattern
+
+	// 重复注册时 ServeMux 的 panic 信息难以定位，这里提前给出方法、路径和之前注册的处理器
+	for _, r := range z.routes {
+		if r.Method == method && r.Pattern == pattern {
+			panic(fmt.Sprintf("zest: route %q is already registered (handler %s)", route, r.Handler))
+		}
+	}
+
+	z.mux.HandleFunc(route, h)
+
+	if !slices.Contains(z.methods, method) {
+		z.methods = append(z.methods, method)
+	}
+
+	r := &Route{Method: method, Pattern: pattern, Handler: name}
+	z.routes = append(z.routes, r)
+	return r
+}
+
+// trailingSlashTarget 切换请求路径末尾的 / 后重新匹配，能匹配到同方法的路由时返回重定向地址
+func (z *Zest) trailingSlashTarget(r *http.Request) (string, bool) {
+	p := r.URL.Path
+	if p == "/" || p == "" {
+		return "", false
+	}
+	if strings.HasSuffix(p, "/") {
+		p = strings.TrimSuffix(p, "/")
+	} else {
+		p += "/"
+	}
+
+	u := *r.URL
+	u.Path = p
+	u.RawPath = ""
+	probe := *r
+	probe.URL = &u
+	_, pattern := z.mux.Handler(&probe)
+	// 兜底的 "/" 不带方法，不会被误判为匹配；AutoHead 时 HEAD 请求匹配的是 GET 路由
+	if !strings.HasPrefix(pattern, r.Method+" ") &&
+		!(r.Method == http.MethodHead && strings.HasPrefix(pattern, http.MethodGet+" ")) {
+		return "", false
+	}
+
+	target := u.EscapedPath()
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return target, true
+}
+
+// allowedMethods 返回能够匹配该请求路径的方法
+// 方法不匹配的请求会落到全局兜底 "/"，这里换成每个已注册的方法向 ServeMux 重新查询一次，
+// 不需要为每个路径额外注册兜底路由，也就不会和 "GET /users/{id}"、"GET /users/me" 这类模式冲突
+func (z *Zest) allowedMethods(r *http.Request) []string {
+	var methods []string
+	probe := *r
+	for _, method := range z.methods {
+		probe.Method = method
+		// ServeMux 会把 HEAD 请求交给 GET 路由，只有匹配到同方法的模式才算允许
+		if _, pattern := z.mux.Handler(&probe); strings.HasPrefix(pattern, method+" ") {
+			methods = append(methods, method)
+		}
+	}
+	// 开启 AutoHead 时 GET 路由同样会响应 HEAD 请求
+	if z.AutoHead && slices.Contains(methods, http.MethodGet) && !slices.Contains(methods, http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+// methodNotAllowed 设置 Allow 头后交给 MethodNotAllowed 处理器
+func (z *Zest) methodNotAllowed(c *Context, methods []string) {
+	c.SetHeader(HeaderAllow, strings.Join(methods, ", "))
+
+	if err := z.methodNotAllowedHandler(c); err != nil {
+		c.Error(err)
+	}
+}
+
+// headResponseWriter 丢弃响应体的 ResponseWriter，ServeHTTP 用它包装所有 HEAD 请求的原始 ResponseWriter
+// 处理器和中间件照常写入，写入的字节数仍由 Response 统计，日志中的 Size 与对应 GET 请求一致
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w headResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (z *Zest) GET(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return z.handle(http.MethodGet, pattern, handler, mws...)
+}
+
+func (z *Zest) POST(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return z.handle(http.MethodPost, pattern, handler, mws...)
 }
 
-func (z *Zest) POST(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	z.handle(http.MethodPost, pattern, handler, mws...)
+func (z *Zest) PUT(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return z.handle(http.MethodPut, pattern, handler, mws...)
 }
 
-func (z *Zest) PUT(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	z.handle(http.MethodPut, pattern, handler, mws...)
+func (z *Zest) PATCH(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return z.handle(http.MethodPatch, pattern, handler, mws...)
 }
 
-func (z *Zest) PATCH(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	z.handle(http.MethodPatch, pattern, handler, mws...)
+func (z *Zest) DELETE(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return z.handle(http.MethodDelete, pattern, handler, mws...)
 }
 
-func (z *Zest) DELETE(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	z.handle(http.MethodDelete, pattern, handler, mws...)
+func (z *Zest) OPTIONS(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return z.handle(http.MethodOptions, pattern, handler, mws...)
 }
 
-func (z *Zest) OPTIONS(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) {
-	z.handle(http.MethodOptions, pattern, handler, mws...)
+// HEAD 显式注册 HEAD 路由，优先于 AutoHead 对 GET 路由的自动响应
+func (z *Zest) HEAD(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return z.handle(http.MethodHead, pattern, handler, mws...)
 }
 
-func (z *Zest) Run(addr string) error {
-	log.Printf("🚀 Zest server listening on %s\n", addr)
-	return http.ListenAndServe(addr, z)
+// Any 为 GET、POST、PUT、PATCH、DELETE、OPTIONS、HEAD 注册同一个处理函数
+func (z *Zest) Any(pattern string, handler HandlerFunc, mws ...MiddlewareFunc) []*Route {
+	return z.Match(anyMethods, pattern, handler, mws...)
 }
 
+// Match 为指定的多个方法注册同一个处理函数
+func (z *Zest) Match(methods []string, pattern string, handler HandlerFunc, mws ...MiddlewareFunc) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, z.handle(method, pattern, handler, mws...))
+	}
+	return routes
+}
+
+// anyMethods Any 注册的方法列表
+var anyMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodHead,
+}
+
+// Routes 返回所有已注册路由的副本，按 Pattern、Method 排序
+// 兜底的 404 处理不会出现在结果中
+func (z *Zest) Routes() []Route {
+	routes := make([]Route, 0, len(z.routes))
+	for _, r := range z.routes {
+		routes = append(routes, *r)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// PrintRoutes 以表格形式输出所有路由的方法、路径和处理器函数名，方法按颜色区分
+// 开启 Debug 时 Run、RunTLS 等启动方法会自动输出到标准输出
+func (z *Zest) PrintRoutes(w io.Writer) {
+	routes := z.Routes()
+	methodWidth, patternWidth := 0, 0
+	for _, r := range routes {
+		methodWidth = max(methodWidth, len(r.Method))
+		patternWidth = max(patternWidth, len(r.Pattern))
+	}
+
+	var b strings.Builder
+	for _, r := range routes {
+		// 颜色码放在填充之外，保证各列对齐
+		fmt.Fprintf(&b, "%s%-*s%s  %-*s  %s\n",
+			methodColor(r.Method), methodWidth, r.Method, colorReset,
+			patternWidth, r.Pattern, r.Handler)
+	}
+	io.WriteString(w, b.String())
+}
+
+const colorReset = "\033[0m"
+
+// methodColor 与 middleware.Logger 中的方法颜色保持一致
+func methodColor(method string) string {
+	switch method {
+	case http.MethodGet:
+		return "\033[96m"
+	case http.MethodPost:
+		return "\033[92m"
+	case http.MethodPut:
+		return "\033[93m"
+	case http.MethodDelete:
+		return "\033[91m"
+	case http.MethodPatch:
+		return "\033[95m"
+	case http.MethodHead:
+		return "\033[94m"
+	default:
+		return colorReset
+	}
+}
+
+// handlerName 返回处理器的函数名，匿名函数形如 "main.main.func1"
+func handlerName(h HandlerFunc) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// SetTrustedProxies 设置 TrustedProxies 并立即解析，格式错误时返回错误且不修改原有配置
+// 只有来自可信代理的请求，c.RealIP 才会读取 X-Forwarded-For / X-Real-Ip
+// 未设置时转发头会被完全忽略，防止客户端伪造 IP
+func (z *Zest) SetTrustedProxies(proxies ...string) error {
+	prefixes, err := parseTrustedProxies(proxies)
+	if err != nil {
+		return err
+	}
+	z.TrustedProxies = proxies
+	z.trustedProxies = prefixes
+	z.trustedProxiesErr = nil
+	return nil
+}
+
+// loadTrustedProxies 第一次调用时解析 TrustedProxies，之后返回第一次解析的错误
+// 解析失败时 trustedProxies 保持不变，即不信任任何代理
+func (z *Zest) loadTrustedProxies() error {
+	z.trustedProxiesOnce.Do(func() {
+		if len(z.TrustedProxies) == 0 {
+			return
+		}
+		prefixes, err := parseTrustedProxies(z.TrustedProxies)
+		if err != nil {
+			z.trustedProxiesErr = err
+			return
+		}
+		z.trustedProxies = prefixes
+	})
+	return z.trustedProxiesErr
+}
+
+// parseTrustedProxies 把 CIDR 和单个 IP 解析成网段
+func parseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if strings.Contains(p, "/") {
+			prefix, err := netip.ParsePrefix(p)
+			if err != nil {
+				return nil, fmt.Errorf("zest: invalid trusted proxy %q: %w", p, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(p)
+		if err != nil {
+			return nil, fmt.Errorf("zest: invalid trusted proxy %q: %w", p, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+func (z *Zest) isTrustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range z.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Use 在全局中间件链的末尾追加中间件，先注册的在外层，先于后注册的执行
 func (z *Zest) Use(mws ...MiddlewareFunc) {
 	z.middlewares = append(z.middlewares, mws...)
 }
 
+// UsePrepend 将中间件插入到全局中间件链的最前面，成为最外层
+// z.Use(A, B) 之后再 z.UsePrepend(X, Y)，执行顺序为 X → Y → A → B → 路由
+// 适合 Recovery、RequestID 等无论用户如何注册都必须包裹所有中间件的场景
+func (z *Zest) UsePrepend(mws ...MiddlewareFunc) {
+	// 总是分配新切片，不会覆盖旧切片底层数组中的数据
+	z.middlewares = slices.Concat(mws, z.middlewares)
+}
+
 // Group 创建路由分组
 func (z *Zest) Group(prefix string, mws ...MiddlewareFunc) *Group {
 	return &Group{
 		prefix:      prefix,
-		middlewares: mws,
+		middlewares: mergeMiddlewares(nil, mws),
 		zest:        z,
 	}
 }
@@ -133,6 +631,99 @@ func (z *Zest) Group(prefix string, mws ...MiddlewareFunc) *Group {
 // Static 静态文件服务
 // 建议直接使用 middleware.Static 中间件获得更多配置项
 func (z *Zest) Static(prefix, root string) {
+	prefix = staticPrefix(prefix)
+	z.GET(prefix+"{path...}", z.staticHandler(root))
+}
+
+// StaticFS 使用 fs.FS 提供静态文件服务，可用于 //go:embed 嵌入的资源
+// 嵌入的文件没有修改时间，此时会根据文件大小生成 ETag 代替 Last-Modified
+func (z *Zest) StaticFS(prefix string, fsys fs.FS) {
+	prefix = staticPrefix(prefix)
+	z.GET(prefix+"{path...}", z.staticFSHandler(fsys))
+}
+
+// staticHandler 返回 root 目录的静态文件处理函数，文件路径取自路由参数 {path...}
+func (z *Zest) staticHandler(root string) HandlerFunc {
+	handler := http.FileServer(http.Dir(root))
+
+	return func(c *Context) error {
+		if err := z.checkStaticPath(c.Param("path")); err != nil {
+			return err
+		}
+		serveStatic(c, handler)
+		return nil
+	}
+}
+
+// staticFSHandler 返回 fsys 的静态文件处理函数，文件路径取自路由参数 {path...}
+func (z *Zest) staticFSHandler(fsys fs.FS) HandlerFunc {
+	handler := http.FileServer(http.FS(fsys))
+
+	return func(c *Context) error {
+		if err := z.checkStaticPath(c.Param("path")); err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(c.Param("path"), "/")
+		if name == "" {
+			name = "."
+		}
+		setFallbackETag(c, fsys, name)
+		serveStatic(c, handler)
+		return nil
+	}
+}
+
+// serveStatic 以路由参数 {path...} 作为请求路径交给 http.FileServer
+// 不使用 http.StripPrefix，前缀中包含通配符（如分组 /tenant/{id}）时同样适用
+func serveStatic(c *Context, handler http.Handler) {
+	u := *c.Request.URL
+	u.Path = "/" + c.Param("path")
+	u.RawPath = ""
+	r := *c.Request
+	r.URL = &u
+	handler.ServeHTTP(c.Response(), &r)
+}
+
+// FileFS 将 fs.FS 中的单个文件注册到 path 上，常用于单页应用的 index.html 等 //go:embed 嵌入的文件
+// 与 StaticFS 一样，文件没有修改时间时根据文件大小生成 ETag；写入经过 c.Response()，日志等中间件能看到实际的状态码和大小
+//
+//	//go:embed dist
+//	var dist embed.FS
+//	z.FileFS("/", "dist/index.html", dist)
+func (z *Zest) FileFS(path, file string, fsys fs.FS) {
+	z.GET(path, func(c *Context) error {
+		setFallbackETag(c, fsys, file)
+		http.ServeFileFS(c.Response(), c.Request, fsys, file)
+		return nil
+	})
+}
+
+// setFallbackETag 文件没有修改时间时（如 embed.FS），根据文件大小设置一个弱 ETag
+// http.ServeContent 会据此处理 If-None-Match 条件请求
+func setFallbackETag(c *Context, fsys fs.FS, name string) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil || info.IsDir() || !info.ModTime().IsZero() {
+		return
+	}
+	c.SetHeader("ETag", `W/"`+strconv.FormatInt(info.Size(), 16)+`"`)
+}
+
+// checkStaticPath 检查静态文件路径
+// 包含 .. 段时返回 400，不单纯依赖 ServeMux 和 path.Clean 的清理；开启 DenyDotfiles 时以 . 开头的段返回 404
+func (z *Zest) checkStaticPath(p string) error {
+	for seg := range strings.SplitSeq(strings.ReplaceAll(p, "\\", "/"), "/") {
+		if seg == ".." {
+			return NewHTTPError(http.StatusBadRequest, "invalid path")
+		}
+		if z.DenyDotfiles && strings.HasPrefix(seg, ".") {
+			return NewHTTPError(http.StatusNotFound, "not found")
+		}
+	}
+	return nil
+}
+
+// staticPrefix 规范化静态文件前缀，确保以 / 开头和结尾
+func staticPrefix(prefix string) string {
 	if prefix == "" {
 		prefix = "/"
 	}
@@ -144,14 +735,15 @@ func (z *Zest) Static(prefix, root string) {
 	if !strings.HasSuffix(prefix, "/") {
 		prefix += "/"
 	}
+	return prefix
+}
 
-	fileServer := http.FileServer(http.Dir(root))
-	handler := http.StripPrefix(prefix, fileServer)
-
-	z.GET(prefix+"{path...}", func(c *Context) error {
-		handler.ServeHTTP(c.ResponseWriter(), c.Request)
-		return nil
-	})
+// mergeMiddlewares 返回一个新切片，依次包含 a 和 b 中的中间件
+// 直接 append(a, b...) 在容量足够时会复用 a 的底层数组，导致不同路由的中间件互相覆盖
+func mergeMiddlewares(a, b []MiddlewareFunc) []MiddlewareFunc {
+	merged := make([]MiddlewareFunc, 0, len(a)+len(b))
+	merged = append(merged, a...)
+	return append(merged, b...)
 }
 
 func use(handler HandlerFunc, mws ...MiddlewareFunc) HandlerFunc {
@@ -0,0 +1,115 @@
+package zest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// record 返回把 name 追加到 *trace 的中间件
+func record(trace *[]string, name string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			*trace = append(*trace, name)
+			return next(c)
+		}
+	}
+}
+
+func TestRouteMiddlewareIsolation(t *testing.T) {
+	var trace []string
+	z := New()
+	z.Use(record(&trace, "global"))
+	// 多次 Use 让底层数组留有余量，旧实现在这种情况下会让后注册的路由覆盖前一个路由的中间件
+	z.Use(record(&trace, "global2"))
+	z.Use(record(&trace, "global3"))
+
+	g := z.Group("/g")
+	g.Use(record(&trace, "group"))
+	g.Use(record(&trace, "group2"))
+	g.Use(record(&trace, "group3"))
+
+	// 同一个父分组下的兄弟分组先全部创建再注册路由，共享底层数组时后创建的会覆盖先创建的
+	x := g.Group("/x", record(&trace, "x"))
+	y := g.Group("/y", record(&trace, "y"))
+
+	ok := func(c *Context) error { return c.NoContent(http.StatusOK) }
+	z.GET("/a", ok, record(&trace, "a"))
+	z.GET("/b", ok, record(&trace, "b"))
+	g.GET("/c", ok, record(&trace, "c"))
+	g.GET("/d", ok, record(&trace, "d"))
+	x.GET("/e", ok)
+	y.GET("/e", ok)
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/a", []string{"global", "global2", "global3", "a"}},
+		{"/b", []string{"global", "global2", "global3", "b"}},
+		{"/g/c", []string{"global", "global2", "global3", "group", "group2", "group3", "c"}},
+		{"/g/d", []string{"global", "global2", "global3", "group", "group2", "group3", "d"}},
+		{"/g/x/e", []string{"global", "global2", "global3", "group", "group2", "group3", "x"}},
+		{"/g/y/e", []string{"global", "global2", "global3", "group", "group2", "group3", "y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			trace = nil
+			rec := z.TestRequest(http.MethodGet, tt.target, nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			if !slices.Equal(trace, tt.want) {
+				t.Errorf("middlewares = [%s], want [%s]", strings.Join(trace, " "), strings.Join(tt.want, " "))
+			}
+		})
+	}
+}
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	tests := []struct {
+		name         string
+		enabled      bool
+		method       string
+		target       string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"strip slash", true, http.MethodGet, "/users/", http.StatusPermanentRedirect, "/users"},
+		// 补上 / 的方向由 ServeMux 处理，与选项无关
+		{"add slash", true, http.MethodGet, "/posts", http.StatusTemporaryRedirect, "/posts/"},
+		{"add slash disabled", false, http.MethodGet, "/posts", http.StatusTemporaryRedirect, "/posts/"},
+		{"add slash keeps method", true, http.MethodPut, "/files", http.StatusTemporaryRedirect, "/files/"},
+		{"param route", true, http.MethodGet, "/users/5/", http.StatusPermanentRedirect, "/users/5"},
+		{"query kept", true, http.MethodGet, "/users/?page=2", http.StatusPermanentRedirect, "/users?page=2"},
+		{"post keeps method", true, http.MethodPost, "/items/", http.StatusPermanentRedirect, "/items"},
+		{"other method does not redirect", true, http.MethodPost, "/users/", http.StatusNotFound, ""},
+		{"exact match", true, http.MethodGet, "/users", http.StatusOK, ""},
+		{"no match either way", true, http.MethodGet, "/missing/", http.StatusNotFound, ""},
+		{"disabled", false, http.MethodGet, "/users/", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := New()
+			z.RedirectTrailingSlash = tt.enabled
+			ok := func(c *Context) error { return c.String(http.StatusOK, "ok") }
+			z.GET("/users", ok)
+			z.GET("/users/{id}", ok)
+			z.GET("/posts/{$}", ok)
+			z.POST("/items", ok)
+			z.PUT("/files/", ok)
+
+			rec := z.TestRequest(tt.method, tt.target, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get(HeaderLocation); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}